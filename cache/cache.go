@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
 var cachePath = filepath.Join(os.TempDir(), "pullkee_cache", "cache.json")
@@ -15,6 +17,9 @@ var cachePath = filepath.Join(os.TempDir(), "pullkee_cache", "cache.json")
 type Cache interface {
 	Set(string, interface{}) error
 	Get(string, interface{}) (bool, error)
+	Delete(string) error
+	Clear() error
+	Keys() ([]string, error)
 }
 
 // FS is an interface for interacting with the file system
@@ -22,6 +27,15 @@ type FS interface {
 	Mkdir(path string, perms os.FileMode) error
 	WriteFile(path string, data []byte, perms os.FileMode) error
 	ReadFile(path string) ([]byte, error)
+	Remove(path string) error
+	ReadDir(path string) ([]os.FileInfo, error)
+}
+
+// Entry describes a single entry of FSCache
+type Entry struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
 }
 
 // FSCache is an implementation of file-system cache using the FS interface
@@ -49,8 +63,7 @@ func (c FSCache) Set(key string, x interface{}) error {
 func (c FSCache) Get(key string, x interface{}) (bool, error) {
 	data, err := c.FS.ReadFile(c.filePath(key))
 	if err != nil {
-		// "no such file or directory" just should mean there's no cache entry
-		if strings.Contains(err.Error(), "no such file or directory") {
+		if notExist(err) {
 			return false, nil
 		}
 		return false, err
@@ -59,6 +72,76 @@ func (c FSCache) Get(key string, x interface{}) (bool, error) {
 	return true, json.Unmarshal(data, x)
 }
 
+// Delete removes the file of `key` (if exists)
+func (c FSCache) Delete(key string) error {
+	if err := c.FS.Remove(c.filePath(key)); err != nil && !notExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Clear removes all the entries
+func (c FSCache) Clear() error {
+	keys, err := c.Keys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := c.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Keys lists the keys of all the entries
+func (c FSCache) Keys() ([]string, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	return keys, nil
+}
+
+// Entries lists all the entries along with their size and modification time, sorted by the key
+func (c FSCache) Entries() ([]Entry, error) {
+	files, err := c.FS.ReadDir(c.CachePath)
+	if err != nil {
+		if notExist(err) {
+			return []Entry{}, nil
+		}
+		return nil, err
+	}
+
+	entries := []Entry{}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		entries = append(entries, Entry{
+			Key:     strings.TrimSuffix(f.Name(), ".json"),
+			Size:    f.Size(),
+			ModTime: f.ModTime(),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries, nil
+}
+
 func (c FSCache) filePath(key string) string {
 	return filepath.Join(c.CachePath, fmt.Sprintf("%s.json", key))
 }
+
+// notExist tells if the error is "no such file or directory" which just should mean there's no cache entry
+func notExist(err error) bool {
+	return os.IsNotExist(err) || strings.Contains(err.Error(), "no such file or directory")
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -137,6 +140,109 @@ func TestRead(t *testing.T) {
 	})
 }
 
+func TestDelete(t *testing.T) {
+	t.Run("Removes the file", func(t *testing.T) {
+		m := mockFS{
+			cache: map[string]cacheEntity{
+				"/tmp/key1.json": {[]byte(`{"x":"val1"}`), 0777},
+			},
+		}
+		c := FSCache{
+			FS:        &m,
+			CachePath: "/tmp/",
+		}
+
+		require.Nil(t, c.Delete("key1"))
+		require.Len(t, m.cache, 0)
+	})
+
+	t.Run("Doesn't fail when the file didn't exist", func(t *testing.T) {
+		c := FSCache{
+			FS:        &mockFS{cache: map[string]cacheEntity{}},
+			CachePath: "/tmp/",
+		}
+
+		require.Nil(t, c.Delete("key1"))
+	})
+
+	t.Run("Fails when couldn't remove the file", func(t *testing.T) {
+		c := FSCache{
+			FS:        &mockFS{removeErr: fmt.Errorf("Some weird FS error")},
+			CachePath: "/tmp/",
+		}
+
+		require.EqualError(t, c.Delete("key1"), "Some weird FS error")
+	})
+}
+
+func TestEntries(t *testing.T) {
+	t.Run("Lists the entries sorted by the key", func(t *testing.T) {
+		modTime := time.Date(2018, 4, 28, 0, 0, 0, 0, time.UTC)
+		m := mockFS{
+			cache: map[string]cacheEntity{
+				"/tmp/key2.json":       {[]byte(`{}`), 0777},
+				"/tmp/key1.json":       {[]byte(`{"x":"val1"}`), 0777},
+				"/tmp/notes.txt":       {[]byte(`Not an entry`), 0777},
+				"/tmp/other/key3.json": {[]byte(`{}`), 0777},
+			},
+			modTime: modTime,
+		}
+		c := FSCache{
+			FS:        &m,
+			CachePath: "/tmp/",
+		}
+
+		entries, err := c.Entries()
+		require.Nil(t, err)
+		require.Equal(t, []Entry{
+			{Key: "key1", Size: 12, ModTime: modTime},
+			{Key: "key2", Size: 2, ModTime: modTime},
+		}, entries)
+
+		keys, err := c.Keys()
+		require.Nil(t, err)
+		require.Equal(t, []string{"key1", "key2"}, keys)
+	})
+
+	t.Run("Doesn't fail when the directory didn't exist", func(t *testing.T) {
+		c := FSCache{
+			FS:        &mockFS{readDirErr: fmt.Errorf("/tmp/: no such file or directory")},
+			CachePath: "/tmp/",
+		}
+
+		keys, err := c.Keys()
+		require.Nil(t, err)
+		require.Len(t, keys, 0)
+	})
+
+	t.Run("Fails when couldn't read the directory", func(t *testing.T) {
+		c := FSCache{
+			FS:        &mockFS{readDirErr: fmt.Errorf("Some weird FS error")},
+			CachePath: "/tmp/",
+		}
+
+		_, err := c.Keys()
+		require.EqualError(t, err, "Some weird FS error")
+	})
+}
+
+func TestClear(t *testing.T) {
+	m := mockFS{
+		cache: map[string]cacheEntity{
+			"/tmp/key1.json":       {[]byte(`{}`), 0777},
+			"/tmp/key2.json":       {[]byte(`{}`), 0777},
+			"/tmp/other/key3.json": {[]byte(`{}`), 0777},
+		},
+	}
+	c := FSCache{
+		FS:        &m,
+		CachePath: "/tmp/",
+	}
+
+	require.Nil(t, c.Clear())
+	require.Equal(t, []string{"/tmp/other/key3.json"}, m.paths())
+}
+
 type cacheEntity struct {
 	data []byte
 	perm os.FileMode
@@ -148,6 +254,9 @@ type mockFS struct {
 	mkdirErr     error
 	writeFileErr error
 	readFileErr  error
+	removeErr    error
+	readDirErr   error
+	modTime      time.Time
 }
 
 func (m *mockFS) Mkdir(path string, perms os.FileMode) error {
@@ -173,3 +282,52 @@ func (m *mockFS) ReadFile(key string) ([]byte, error) {
 type testStruct struct {
 	X string `json:"x"`
 }
+
+func (m *mockFS) Remove(key string) error {
+	if m.removeErr != nil {
+		return m.removeErr
+	}
+	if _, ok := m.cache[key]; !ok {
+		return fmt.Errorf("%s: no such file or directory", key)
+	}
+	delete(m.cache, key)
+	return nil
+}
+
+func (m *mockFS) ReadDir(path string) ([]os.FileInfo, error) {
+	if m.readDirErr != nil {
+		return nil, m.readDirErr
+	}
+
+	files := []os.FileInfo{}
+	for key, entity := range m.cache {
+		if filepath.Dir(key) == filepath.Clean(path) {
+			files = append(files, mockFileInfo{filepath.Base(key), entity, m.modTime})
+		}
+	}
+	return files, nil
+}
+
+// paths lists the files in the mock FS in the alphabetical order
+func (m *mockFS) paths() []string {
+	paths := []string{}
+	for key := range m.cache {
+		paths = append(paths, key)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// mockFileInfo is an os.FileInfo of a file in mockFS
+type mockFileInfo struct {
+	name    string
+	entity  cacheEntity
+	modTime time.Time
+}
+
+func (f mockFileInfo) Name() string       { return f.name }
+func (f mockFileInfo) Size() int64        { return int64(len(f.entity.data)) }
+func (f mockFileInfo) Mode() os.FileMode  { return f.entity.perm }
+func (f mockFileInfo) ModTime() time.Time { return f.modTime }
+func (f mockFileInfo) IsDir() bool        { return false }
+func (f mockFileInfo) Sys() interface{}   { return nil }
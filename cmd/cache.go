@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kirillrogovoy/pullkee/cache"
+	"github.com/kirillrogovoy/pullkee/github"
+)
+
+// repoCache is the cache of a single repo
+type repoCache struct {
+	name string // e.g. "facebook/react" or "github.example.com/team/repo"
+	cache.FSCache
+}
+
+// manageCache runs one of the "pullkee cache" subcommands on the given repo or all the cached ones
+func manageCache(action string, f flags) {
+	switch action {
+	case "ls", "stats", "clear", "prune":
+	default:
+		fmt.Printf("Unknown cache command %q, expected one of: ls, stats, clear, prune\n\n%s\n", action, usage)
+		os.Exit(1)
+	}
+
+	if action == "prune" && f.olderThan.IsZero() {
+		fmt.Printf("--older-than is required for prune\n\n%s\n", usage)
+		os.Exit(1)
+	}
+
+	repos, err := getCachedRepos(f)
+	if err != nil {
+		reportErrorAndExit(err)
+	}
+
+	switch action {
+	case "ls":
+		for _, r := range repos {
+			fmt.Println(r.name)
+		}
+	case "stats":
+		err = printCacheStats(repos)
+	case "clear":
+		for _, r := range repos {
+			if err = r.Clear(); err != nil {
+				break
+			}
+			fmt.Printf("Cleared %s\n", r.name)
+		}
+	case "prune":
+		for _, r := range repos {
+			var pruned int
+			if pruned, err = prune(r.FSCache, f.olderThan); err != nil {
+				break
+			}
+			fmt.Printf("Pruned %d entries of %s\n", pruned, r.name)
+		}
+	}
+
+	if err != nil {
+		reportErrorAndExit(err)
+	}
+}
+
+// getCachedRepos returns the cache of the repo given in the arguments or, if there is none, of all the cached repos
+func getCachedRepos(f flags) ([]repoCache, error) {
+	if flag.Arg(0) != "" {
+		repo, apiURL := getRepo(f)
+		name := repo
+		if apiURL != github.DefaultBaseURL {
+			name = webHost(apiURL) + "/" + repo
+		}
+		return []repoCache{{name, getCache(repo, apiURL)}}, nil
+	}
+
+	repos := []repoCache{}
+	err := filepath.Walk(cacheRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		// a directory with some entries in it is a repo
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+				name, _ := filepath.Rel(cacheRoot, path)
+				repos = append(repos, repoCache{
+					filepath.ToSlash(name),
+					cache.FSCache{CachePath: path, FS: RealFS{}},
+				})
+				break
+			}
+		}
+		return nil
+	})
+
+	return repos, err
+}
+
+// printCacheStats prints the number of entries, their total size and the oldest one per repo
+func printCacheStats(repos []repoCache) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tENTRIES\tSIZE\tOLDEST")
+
+	for _, r := range repos {
+		entries, err := r.Entries()
+		if err != nil {
+			return err
+		}
+
+		size := int64(0)
+		oldest := time.Time{}
+		for _, e := range entries {
+			size += e.Size
+			if oldest.IsZero() || e.ModTime.Before(oldest) {
+				oldest = e.ModTime
+			}
+		}
+
+		since := "-"
+		if !oldest.IsZero() {
+			since = oldest.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", r.name, len(entries), formatSize(size), since)
+	}
+
+	return w.Flush()
+}
+
+// prune deletes the entries which weren't updated since `before` and returns how many of them there were
+func prune(c cache.FSCache, before time.Time) (int, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	for _, e := range entries {
+		if e.ModTime.Before(before) {
+			if err := c.Delete(e.Key); err != nil {
+				return pruned, err
+			}
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// formatSize formats a number of bytes in a human-readable way, e.g. "1.5 MB"
+func formatSize(bytes int64) string {
+	units := []string{"B", "KB", "MB", "GB"}
+	size := float64(bytes)
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	return fmt.Sprintf("%.1f %s", size, units[unit])
+}
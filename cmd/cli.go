@@ -1,71 +1,313 @@
 package cmd
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/kirillrogovoy/pullkee/github/client"
+	"github.com/kirillrogovoy/pullkee/github"
+	"github.com/kirillrogovoy/pullkee/metric"
+	"github.com/kirillrogovoy/pullkee/report"
 )
 
 const usage = `Usage:
 	pullkee [flags] [repo]
-	repo - Github repository path as "username/reponame"
+	pullkee serve [flags] [repo]
+	pullkee list-metrics
+	pullkee cache ls|stats|clear|prune [flags] [repo]
+	repo - Github repository path as "username/reponame" or its URL as "https://github.example.com/username/reponame"
+
+	Commands:
+	serve - Expose the metrics on /metrics in the Prometheus format and refresh them periodically
+	list-metrics - Print the identifiers and the descriptions of all the metrics
+	cache - Manage the local cache of the given repo or of all the cached ones:
+	        ls - list the cached repos, stats - show the number of entries, their size and the oldest one,
+	        clear - remove everything, prune - remove the entries older than --older-than
 
 	Flags:
 	--limit - Only use N last pull requests
+	--reset - Clear the cache of the repo before running
+	--state - Which pull requests to analyze: closed (default), open or all
+	--since - Only use pull requests merged, closed or still open after that date
+	--until - Only use pull requests created before that date
+	          Both accept a date ("2018-04-28"), a timestamp ("2018-04-28T12:00:00Z")
+	          or a time ago ("30d", "2w", "12h")
+	--metrics - Comma-separated identifiers of the metrics to calculate (all by default)
+	--exclude-metrics - Comma-separated identifiers of the metrics to skip
+	--concurrency - How many pull requests to fetch the details of in parallel (default 8)
+	--format - Output format: text (default), json, csv, tsv, html or markdown
+	--output - Write the report to the file instead of stdout
+	--listen - Address to serve on (serve only, default ":9090")
+	--interval - How often to refresh the metrics (serve only, default 15m)
+	--older-than - Age of the cache entries to remove, e.g. "30d" or "2018-04-28" (cache prune only)
+	--timeout - Give up if fetching the data takes longer, e.g. "10m" (no limit by default).
+	            In serve, it applies to every refresh
+	--max-rps - Make at most N requests to the Github API per second (no limit by default).
+	            Regardless of it, the requests are slowed down once a half of the rate limit is used
+	            so that the rest lasts until the reset
+	--verbose - Log every request to the Github API and every retry to stderr
+	--creds-file - Read the API credentials from the file, one "username:personal_access_token" per line
+	--token-file - Read the API tokens (sent as "Authorization: Bearer") from the file, one per line
+	--api-url - Root of the Github API, e.g. "https://github.example.com/api/v3" for Github Enterprise
+	            (default "https://api.github.com" or the one of the repo URL)
+	--api - Which Github API to use: rest (default) or graphql.
+	        GraphQL fetches the pull requests together with their reviews, comments and review requests,
+	        so it takes a lot fewer requests. The diff size is still fetched from the REST API
 
 	Environment variables:
-	GITHUB_CREDS - API credentials in the format "username:personal_access_token"`
+	GITHUB_CREDS - API credentials in the format "username:personal_access_token".
+	               Multiple ones can be separated by commas, pullkee then uses the one with the most requests left
+	GITHUB_TOKEN - API token sent as "Authorization: Bearer", e.g. a fine-grained personal access token
+	               or the one of Github Actions
+	GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID, GITHUB_APP_PRIVATE_KEY_FILE - Authenticate as an installation
+	               of a Github App instead, given the App's ID, the installation ID and the path to the private key
+	GITHUB_API_URL - Default for --api-url
+
+	Without any credentials, pullkee uses the token of the "gh" command line tool if it's logged in`
 
 type flags struct {
-	limit int
-	reset bool
+	limit       int
+	state       string
+	since       string // resolved by window() since it may be relative to the current time
+	until       string
+	reset       bool
+	metrics     []string
+	exclude     []string
+	concurrency int
+	format      string
+	output      string
+	listen      string
+	interval    time.Duration
+	timeout     time.Duration
+	verbose     bool
+	maxRPS      float64
+	credsFile   string
+	tokenFile   string
+	apiURL      string
+	api         string
+	olderThan   time.Time
 }
 
-func getFlags() flags {
+// commands contains all the known subcommands
+var commands = map[string]bool{
+	"serve":        true,
+	"list-metrics": true,
+	"cache":        true,
+}
+
+// getCommand splits the arguments into a subcommand (if any) and the rest.
+// "cache" has subcommands of its own, e.g. "cache ls"
+func getCommand() (string, []string) {
+	args := os.Args[1:]
+	if len(args) > 0 && commands[args[0]] {
+		if args[0] == "cache" && len(args) > 1 && !strings.HasPrefix(args[1], "-") {
+			return args[0] + " " + args[1], args[2:]
+		}
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+func getFlags(args []string) flags {
 	flags := flags{}
 
 	flag.IntVar(&flags.limit, "limit", 0, "")
+	flag.StringVar(&flags.state, "state", github.StateClosed, "")
+	flag.StringVar(&flags.since, "since", "", "")
+	flag.StringVar(&flags.until, "until", "", "")
 	flag.BoolVar(&flags.reset, "reset", false, "")
+	metrics := flag.String("metrics", "", "")
+	exclude := flag.String("exclude-metrics", "", "")
+	flag.IntVar(&flags.concurrency, "concurrency", 8, "")
+	flag.StringVar(&flags.format, "format", "text", "")
+	flag.StringVar(&flags.output, "output", "", "")
+	flag.StringVar(&flags.listen, "listen", ":9090", "")
+	flag.DurationVar(&flags.interval, "interval", 15*time.Minute, "")
+	flag.DurationVar(&flags.timeout, "timeout", 0, "")
+	flag.BoolVar(&flags.verbose, "verbose", false, "")
+	flag.Float64Var(&flags.maxRPS, "max-rps", 0, "")
+	flag.StringVar(&flags.credsFile, "creds-file", "", "")
+	flag.StringVar(&flags.tokenFile, "token-file", "", "")
+	flag.StringVar(&flags.apiURL, "api-url", os.Getenv("GITHUB_API_URL"), "")
+	flag.StringVar(&flags.api, "api", "rest", "")
+	olderThan := flag.String("older-than", "", "")
 
 	flag.Usage = func() {
 		fmt.Println(usage)
 	}
-	flag.Parse()
+	flag.CommandLine.Parse(args)
+
+	var err error
+	now := time.Now()
+	if _, err = parseTime(flags.since, now, false); err != nil {
+		fmt.Printf("Invalid --since: %s\n\n%s\n", err, usage)
+		os.Exit(1)
+	}
+	if _, err = parseTime(flags.until, now, true); err != nil {
+		fmt.Printf("Invalid --until: %s\n\n%s\n", err, usage)
+		os.Exit(1)
+	}
+	if flags.olderThan, err = parseTime(*olderThan, now, false); err != nil {
+		fmt.Printf("Invalid --older-than: %s\n\n%s\n", err, usage)
+		os.Exit(1)
+	}
+
+	flags.metrics = splitList(*metrics)
+	flags.exclude = splitList(*exclude)
+	if _, err := metric.Select(flags.metrics, flags.exclude); err != nil {
+		fmt.Printf("%s, run \"pullkee list-metrics\" to see the available ones\n", err)
+		os.Exit(1)
+	}
+
+	if flags.concurrency < 1 {
+		fmt.Printf("--concurrency must be at least 1\n\n%s\n", usage)
+		os.Exit(1)
+	}
+
+	if flags.maxRPS < 0 {
+		fmt.Printf("--max-rps must not be negative\n\n%s\n", usage)
+		os.Exit(1)
+	}
+
+	if flags.api != "rest" && flags.api != "graphql" {
+		fmt.Printf("Invalid --api %q\n\n%s\n", flags.api, usage)
+		os.Exit(1)
+	}
+
+	if flags.timeout < 0 {
+		fmt.Printf("--timeout must not be negative\n\n%s\n", usage)
+		os.Exit(1)
+	}
+
+	if flags.interval <= 0 {
+		fmt.Printf("--interval must be positive\n\n%s\n", usage)
+		os.Exit(1)
+	}
+
+	switch flags.state {
+	case github.StateOpen, github.StateClosed, github.StateAll:
+	default:
+		fmt.Printf("Unknown state %q, expected one of: open, closed, all\n\n%s\n", flags.state, usage)
+		os.Exit(1)
+	}
+
+	if _, ok := report.Renderers[flags.format]; !ok {
+		fmt.Printf(
+			"Unknown format %q, expected one of: %s\n\n%s\n",
+			flags.format,
+			strings.Join(report.Formats(), ", "),
+			usage,
+		)
+		os.Exit(1)
+	}
 
 	return flags
 }
 
-func getGithubCreds() *client.Credentials {
-	creds := os.Getenv("GITHUB_CREDS")
+// selectedMetrics returns new instances of the metrics chosen by --metrics and --exclude-metrics
+func (f flags) selectedMetrics() []metric.Metric {
+	// the identifiers are already validated in getFlags
+	metrics, _ := metric.Select(f.metrics, f.exclude)
+	return metrics
+}
 
-	if creds == "" {
-		return nil
+// splitList splits a comma-separated list ignoring whitespace and empty items
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	if matches, _ := regexp.MatchString(`^[\w-]+:[\w-]+$`, creds); !matches {
-		fmt.Printf("Invalid format of the GITHUB_CREDS environment variable!\n\n%s\n", usage)
-		os.Exit(1)
+// window resolves --since and --until relative to `now`.
+// They are already validated in getFlags
+func (f flags) window(now time.Time) (time.Time, time.Time) {
+	since, _ := parseTime(f.since, now, false)
+	until, _ := parseTime(f.until, now, true)
+	return since, until
+}
+
+var relativeTime = regexp.MustCompile(`^(\d+)([hdw])$`)
+
+// parseTime parses either an absolute date/timestamp or a time ago relative to `now`.
+// A date without time means the beginning of the day, or the end of it if `endOfDay` is set
+func parseTime(value string, now time.Time, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if matches := relativeTime.FindStringSubmatch(value); matches != nil {
+		n, _ := strconv.Atoi(matches[1])
+		unit := map[string]time.Duration{
+			"h": time.Hour,
+			"d": 24 * time.Hour,
+			"w": 7 * 24 * time.Hour,
+		}[matches[2]]
+		return now.Add(-time.Duration(n) * unit), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a date, a timestamp or a time ago, got %q", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// withTimeout limits `ctx` by --timeout if it's set
+func (f flags) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if f.timeout == 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, f.timeout)
+}
 
-	split := strings.Split(creds, ":")
-	return &client.Credentials{
-		Username:            split[0],
-		PersonalAccessToken: split[1],
+// status returns the writer for the progress messages.
+// Unless the output is human-readable or goes to a file, they go to stderr to keep stdout parseable
+func (f flags) status() io.Writer {
+	if f.format == "text" || f.output != "" {
+		return os.Stdout
 	}
+	return os.Stderr
 }
 
-func getRepo() string {
+var repoURL = regexp.MustCompile(`^(https?)://([^/]+)/([\w-\.]+/[\w-\.]+?)(\.git)?/?$`)
+
+// getRepo returns the repository path and the root of the API to get it from.
+// If the repo is given as a URL, the API is derived from its host unless --api-url is set
+func getRepo(f flags) (string, string) {
 	repo := flag.Arg(0)
+	apiURL := f.apiURL
 
 	if repo == "" {
 		fmt.Println(usage)
 		os.Exit(1)
 	}
 
+	if matches := repoURL.FindStringSubmatch(repo); matches != nil {
+		scheme, host := matches[1], matches[2]
+		repo = matches[3]
+		if apiURL == "" && host != "github.com" {
+			apiURL = fmt.Sprintf("%s://%s/api/v3", scheme, host)
+		}
+	}
+
 	if matches, _ := regexp.MatchString(`^[\w-\.]+/[\w-\.]+$`, repo); !matches {
 		fmt.Printf("Invalid format of the repo!\n\n%s\n", usage)
 		os.Exit(1)
@@ -77,5 +319,22 @@ func getRepo() string {
 		os.Exit(1)
 	}
 
-	return repo
+	if apiURL == "" {
+		apiURL = github.DefaultBaseURL
+	}
+	if _, err := url.Parse(apiURL); err != nil || !strings.HasPrefix(apiURL, "http") {
+		fmt.Printf("Invalid --api-url %q\n\n%s\n", apiURL, usage)
+		os.Exit(1)
+	}
+
+	return repo, strings.TrimSuffix(apiURL, "/")
+}
+
+// webHost returns the host of the Github instance the API belongs to, e.g. "github.com" for "https://api.github.com"
+func webHost(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Host, "api.")
 }
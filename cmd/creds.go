@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kirillrogovoy/pullkee/github/client"
+)
+
+// getGithubCreds collects the credentials from all the sources given by the user.
+// If there are none, it falls back to the token the "gh" command line tool is logged in with to `host`
+func getGithubCreds(f flags, host string) []client.Credentials {
+	creds := []client.Credentials{}
+
+	add := func(parse func([]string) ([]client.Credentials, error), list []string, source string) {
+		parsed, err := parse(list)
+		if err != nil {
+			fmt.Printf("Invalid format of %s: %s\n\n%s\n", source, err, usage)
+			os.Exit(1)
+		}
+		creds = append(creds, parsed...)
+	}
+
+	if f.credsFile != "" {
+		add(parseCreds, readLines(f.credsFile), "the credentials file")
+	}
+	if env := os.Getenv("GITHUB_CREDS"); env != "" {
+		add(parseCreds, splitList(env), "the GITHUB_CREDS environment variable")
+	}
+	if f.tokenFile != "" {
+		add(parseTokens, readLines(f.tokenFile), "the token file")
+	}
+	if env := os.Getenv("GITHUB_TOKEN"); env != "" {
+		add(parseTokens, splitList(env), "the GITHUB_TOKEN environment variable")
+	}
+
+	if len(creds) == 0 {
+		if token := ghToken(ghHostsPath(), host); token != "" {
+			creds = append(creds, client.Credentials{Token: token})
+		}
+	}
+
+	return creds
+}
+
+// getGithubApp reads the credentials of a Github App installation from the environment variables.
+// It returns nil if they aren't set
+func getGithubApp(apiURL string) *client.AppCredentials {
+	appID := os.Getenv("GITHUB_APP_ID")
+	if appID == "" {
+		return nil
+	}
+
+	app := &client.AppCredentials{BaseURL: apiURL}
+	var err error
+	if app.AppID, err = strconv.ParseInt(appID, 10, 64); err != nil {
+		fmt.Printf("Invalid format of the GITHUB_APP_ID environment variable!\n\n%s\n", usage)
+		os.Exit(1)
+	}
+	if app.InstallationID, err = strconv.ParseInt(os.Getenv("GITHUB_APP_INSTALLATION_ID"), 10, 64); err != nil {
+		fmt.Printf("Invalid or missing GITHUB_APP_INSTALLATION_ID environment variable!\n\n%s\n", usage)
+		os.Exit(1)
+	}
+
+	keyFile := os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE")
+	if keyFile == "" {
+		fmt.Printf("Missing GITHUB_APP_PRIVATE_KEY_FILE environment variable!\n\n%s\n", usage)
+		os.Exit(1)
+	}
+	key, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		fmt.Printf("Couldn't read %s: %s\n", keyFile, err)
+		os.Exit(1)
+	}
+	if app.PrivateKey, err = client.ParsePrivateKey(key); err != nil {
+		fmt.Printf("Couldn't parse the private key of the Github App: %s\n", err)
+		os.Exit(1)
+	}
+
+	return app
+}
+
+var (
+	credsFormat = regexp.MustCompile(`^[^:\s]+:[^:\s]+$`)
+	tokenFormat = regexp.MustCompile(`^[^:\s]+$`)
+)
+
+// parseCreds parses a list of "username:personal_access_token" items.
+// An item without a username is a token for the bearer auth
+func parseCreds(list []string) ([]client.Credentials, error) {
+	creds := []client.Credentials{}
+	for i, item := range list {
+		if tokenFormat.MatchString(item) {
+			creds = append(creds, client.Credentials{Token: item})
+			continue
+		}
+
+		// the item itself isn't printed since it's a secret
+		if !credsFormat.MatchString(item) {
+			return nil, fmt.Errorf("Expected \"username:personal_access_token\" or a token as item #%d", i+1)
+		}
+
+		split := strings.Split(item, ":")
+		creds = append(creds, client.Credentials{
+			Username:            split[0],
+			PersonalAccessToken: split[1],
+		})
+	}
+	return creds, nil
+}
+
+// parseTokens parses a list of tokens for the bearer auth
+func parseTokens(list []string) ([]client.Credentials, error) {
+	creds := []client.Credentials{}
+	for i, item := range list {
+		if !tokenFormat.MatchString(item) {
+			return nil, fmt.Errorf("Expected a token as item #%d", i+1)
+		}
+		creds = append(creds, client.Credentials{Token: item})
+	}
+	return creds, nil
+}
+
+// readLines reads the non-empty lines of the file skipping the "#" comments
+func readLines(path string) []string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Couldn't read %s: %s\n", path, err)
+		os.Exit(1)
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// ghHostsPath returns the path of the file the "gh" command line tool keeps its credentials in
+func ghHostsPath() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config", "gh", "hosts.yml")
+}
+
+// ghToken finds the oauth_token of the `host` in the "gh" hosts file, which looks like this:
+//
+//	github.com:
+//	    user: someone
+//	    oauth_token: gho_abc
+//
+// It returns an empty string if there is no such file or token
+func ghToken(path string, host string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return parseGhHosts(string(data), host)
+}
+
+// parseGhHosts finds the oauth_token of the `host` in the contents of the "gh" hosts file
+func parseGhHosts(hosts string, host string) string {
+	inHost := false
+	for _, line := range strings.Split(hosts, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// a line without indentation starts a new host
+		if line[0] != ' ' && line[0] != '\t' {
+			inHost = strings.Trim(strings.TrimSuffix(trimmed, ":"), `"'`) == host
+			continue
+		}
+
+		if inHost && strings.HasPrefix(trimmed, "oauth_token:") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "oauth_token:")), `"'`)
+		}
+	}
+	return ""
+}
@@ -22,3 +22,13 @@ func (f RealFS) WriteFile(path string, data []byte, perms os.FileMode) error {
 func (f RealFS) ReadFile(path string) ([]byte, error) {
 	return ioutil.ReadFile(path)
 }
+
+// Remove implementation
+func (f RealFS) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// ReadDir implementation
+func (f RealFS) ReadDir(path string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(path)
+}
@@ -2,13 +2,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kirillrogovoy/pullkee/cache"
@@ -17,86 +20,214 @@ import (
 	"github.com/kirillrogovoy/pullkee/github/util"
 	"github.com/kirillrogovoy/pullkee/metric"
 	"github.com/kirillrogovoy/pullkee/progress"
+	"github.com/kirillrogovoy/pullkee/report"
 	"github.com/pkg/errors"
 )
 
 // Main is the entry function called by the "main" package
 func Main() {
-	flags := getFlags()
+	command, args := getCommand()
+	flags := getFlags(args)
 
-	client := getHTTPClient(getGithubCreds())
-	repo := getRepo()
-	api := getAPI(&client, repo)
+	if command == "list-metrics" {
+		listMetrics()
+		return
+	}
+
+	if strings.HasPrefix(command, "cache") {
+		manageCache(strings.TrimSpace(strings.TrimPrefix(command, "cache")), flags)
+		return
+	}
+
+	ctx := interruptible(context.Background())
+
+	repo, apiURL := getRepo(flags)
+	cache := getCache(repo, apiURL)
+	if flags.reset {
+		if err := cache.Clear(); err != nil {
+			reportErrorAndExit(err)
+		}
+	}
+	client := getHTTPClient(flags, getGithubCreds(flags, webHost(apiURL)), getGithubApp(apiURL), cache)
+	api := getAPI(flags, &client, repo, apiURL)
 
 	// check that we can at least successfully fetch repository's meta information
-	if _, err := api.Repository(); err != nil {
+	repoCtx, cancel := flags.withTimeout(ctx)
+	_, err := api.Repository(repoCtx)
+	cancel()
+	if err != nil {
 		reportErrorAndExit(err)
 	}
 
-	printRateDetails(client)
+	printRateDetails(flags.status(), client)
 
-	cache := getCache(repo)
-	pulls := getPulls(flags, api, cache)
+	if command == "serve" {
+		serve(ctx, flags, repo, api, cache)
+		return
+	}
+
+	ctx, cancel = flags.withTimeout(ctx)
+	defer cancel()
+	pulls := getPulls(ctx, flags, api, cache)
 
-	runMetrics(pulls)
+	runMetrics(flags, pulls)
 }
 
-func getHTTPClient(creds *client.Credentials) client.Client {
-	rateLimiter := time.Tick(time.Millisecond * 100)
-	return client.New(http.DefaultClient, client.Options{
-		Credentials: creds,
-		RateLimiter: &rateLimiter,
+// interruptible returns a context which is cancelled on the first Ctrl-C.
+// The second one kills the process as usual
+func interruptible(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(parent)
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		signal.Stop(interrupts)
+		fmt.Fprintln(os.Stderr, "\nInterrupted, stopping...")
+		cancel()
+	}()
+
+	return ctx
+}
+
+func getHTTPClient(f flags, creds []client.Credentials, app *client.AppCredentials, c cache.Cache) client.Client {
+	var logger client.Log
+	if f.verbose {
+		logger = func(message string) {
+			log.Println(message)
+		}
+	}
+
+	opts := client.Options{
+		Pacing: &client.PacingOptions{
+			MaxRPS:    f.maxRPS,
+			Threshold: 0.5,
+		},
 		MaxRetries:  3,
-	})
+		RetryPolicy: client.DefaultRetryPolicy,
+		Log:         logger,
+		Cache:       c,
+		OnQuotaExhausted: func(resetAt time.Time) {
+			fmt.Fprintf(
+				f.status(),
+				"\nGithub rate limit is exhausted, waiting until %s for it to reset...\n",
+				resetAt.Format("15:04:05"),
+			)
+		},
+	}
+
+	switch {
+	case app != nil:
+		opts.App = app
+	case len(creds) == 1:
+		opts.Credentials = &creds[0]
+	case len(creds) > 1:
+		opts.CredentialsPool = creds
+	}
+
+	return client.New(http.DefaultClient, opts)
 }
 
-func getAPI(client client.HTTPClient, repo string) github.APIv3 {
-	return github.APIv3{
+func getAPI(f flags, client client.HTTPClient, repo string, apiURL string) github.API {
+	v3 := github.APIv3{
 		RepoName:   repo,
 		HTTPClient: client,
+		BaseURL:    apiURL,
+	}
+	if f.api == "graphql" {
+		return github.APIv4{APIv3: v3}
 	}
+	return v3
 }
 
-func getCache(repo string) cache.Cache {
+// cacheRoot is the directory which holds the caches of all the repos
+var cacheRoot = filepath.Join(os.TempDir(), "pullkee_cache")
+
+func getCache(repo string, apiURL string) cache.FSCache {
+	path := filepath.Join(cacheRoot, repo)
+	// the same repo path may exist on several Github instances
+	if apiURL != github.DefaultBaseURL {
+		path = filepath.Join(cacheRoot, webHost(apiURL), repo)
+	}
+
 	return cache.FSCache{
-		CachePath: filepath.Join(os.TempDir(), "pullkee_cache", repo),
+		CachePath: path,
 		FS:        RealFS{},
 	}
 }
 
-func getPulls(f flags, a github.API, c cache.Cache) []github.PullRequest {
-	fmt.Println("Getting Pull Request list...")
+func getPulls(ctx context.Context, f flags, a github.API, c cache.Cache) []github.PullRequest {
+	status := f.status()
+	fmt.Fprintln(status, "Getting Pull Request list...")
 
-	pulls, err := util.Pulls(a, f.limit)
+	bar := progress.Bar{
+		Len: 50,
+		OnChange: func(v string) {
+			fmt.Fprintf(status, "\r%s", v)
+		},
+	}
+
+	pulls, err := fetchPulls(ctx, f, a, c, func() {
+		fmt.Fprintln(status, "Attaching details...")
+		bar.Set(0)
+	}, bar.Set)
 	if err != nil {
 		reportErrorAndExit(err)
 	}
+	fmt.Fprint(status, "\n\n")
 
-	fmt.Println("Attaching details...")
+	return pulls
+}
 
-	bar := progress.Bar{
-		Len: 50,
-		OnChange: func(v string) {
-			fmt.Printf("\r%s", v)
-		},
+// fetchPulls gets the list of pull requests and fills their details.
+// `onList` is called once the list is fetched, `onProgress` - every time another PR is done.
+// A relative time window moves along with every call
+func fetchPulls(
+	ctx context.Context,
+	f flags,
+	a github.API,
+	c cache.Cache,
+	onList func(),
+	onProgress func(float64),
+) ([]github.PullRequest, error) {
+	since, until := f.window(time.Now())
+	pulls, err := util.SyncPulls(ctx, a, c, github.PullRequestsOptions{
+		State: f.state,
+		Limit: f.limit,
+		Since: since,
+		Until: until,
+	})
+	if err != nil {
+		return nil, err
 	}
-	bar.Set(0)
+
+	onList()
 
 	ch := util.FillDetails(
+		ctx,
 		a,
 		c,
 		pulls,
+		metric.Details(f.selectedMetrics()),
+		f.concurrency,
 	)
 
-	for i := range pulls {
-		if err := <-ch; err != nil {
-			reportErrorAndExit(errors.Wrap(err, "filling details for a pull request"))
+	done := 0
+	for err := range ch {
+		if err != nil {
+			return nil, errors.Wrap(err, "filling details for a pull request")
 		}
-		bar.Set(float64(i+1) / float64(len(pulls)))
+		done++
+		onProgress(float64(done) / float64(len(pulls)))
 	}
-	fmt.Print("\n\n")
 
-	return pulls
+	return pulls, nil
+}
+
+func listMetrics() {
+	for _, m := range metric.Metrics() {
+		fmt.Printf("%-22s %s\n", metric.ID(m), m.Description())
+	}
 }
 
 func reportErrorAndExit(err error) {
@@ -104,11 +235,12 @@ func reportErrorAndExit(err error) {
 	os.Exit(1)
 }
 
-func printRateDetails(c client.Client) {
+func printRateDetails(w io.Writer, c client.Client) {
 	l := c.LastResponse.Header
 	resetAt, _ := strconv.Atoi(l.Get("X-RateLimit-Reset"))
 
-	fmt.Printf(
+	fmt.Fprintf(
+		w,
 		"Github rate limit details:\nLimit: %s\nRemaining: %s\nReset: %s\n\n",
 		l.Get("X-RateLimit-Limit"),
 		l.Get("X-RateLimit-Remaining"),
@@ -116,16 +248,30 @@ func printRateDetails(c client.Client) {
 	)
 }
 
-func runMetrics(pullRequests []github.PullRequest) {
-	for _, m := range metric.Metrics() {
-		name := reflect.TypeOf(m).Elem().Name()
-		description := m.Description()
-		err := m.Calculate(pullRequests)
-		fmt.Printf("Metric '%s' (%s)\n", name, description)
-		if err != nil {
-			log.Printf("Error: %s\n", err)
-		} else {
-			fmt.Printf("%s\n", m.String())
+func runMetrics(f flags, pullRequests []github.PullRequest) {
+	entries := report.Build(f.selectedMetrics(), pullRequests)
+
+	render := report.Renderers[f.format]
+	if f.output == "" {
+		if err := render(os.Stdout, entries); err != nil {
+			reportErrorAndExit(errors.Wrap(err, "rendering the report"))
 		}
+		return
 	}
+
+	file, err := os.Create(f.output)
+	if err != nil {
+		reportErrorAndExit(errors.Wrap(err, "creating the output file"))
+	}
+
+	// not deferred: reportErrorAndExit skips the deferred calls and a failed close means the report is lost
+	if err := render(file, entries); err != nil {
+		file.Close()
+		reportErrorAndExit(errors.Wrap(err, "rendering the report"))
+	}
+	if err := file.Close(); err != nil {
+		reportErrorAndExit(errors.Wrap(err, "writing the output file"))
+	}
+
+	fmt.Printf("The report is written to %s\n", f.output)
 }
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kirillrogovoy/pullkee/cache"
+	"github.com/kirillrogovoy/pullkee/github"
+	"github.com/kirillrogovoy/pullkee/report"
+)
+
+// server exposes the last calculated metrics in the Prometheus format
+type server struct {
+	repo string
+
+	mu          sync.RWMutex
+	entries     []report.Entry
+	pulls       int
+	refreshedAt time.Time
+}
+
+// serve calculates the metrics, starts refreshing them every `f.interval`
+// and blocks serving them on `f.listen` until `ctx` is cancelled
+func serve(ctx context.Context, f flags, repo string, a github.API, c cache.Cache) {
+	s := &server{repo: repo}
+
+	log.Println("Calculating the metrics for the first time...")
+	if err := s.refresh(ctx, f, a, c); err != nil {
+		reportErrorAndExit(err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			// the cache makes subsequent refreshes cheap: only the list and the new PRs are fetched
+			if err := s.refresh(ctx, f, a, c); err != nil {
+				log.Printf("Failed to refresh the metrics, serving the previous ones: %s\n", err)
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s)
+	httpServer := &http.Server{Addr: f.listen, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		httpServer.Shutdown(context.Background())
+	}()
+
+	log.Printf("Serving the metrics on %s/metrics\n", f.listen)
+	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
+
+func (s *server) refresh(ctx context.Context, f flags, a github.API, c cache.Cache) error {
+	ctx, cancel := f.withTimeout(ctx)
+	defer cancel()
+
+	pulls, err := fetchPulls(ctx, f, a, c, func() {}, func(float64) {})
+	if err != nil {
+		return err
+	}
+
+	entries := report.Build(f.selectedMetrics(), pulls)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = entries
+	s.pulls = len(pulls)
+	s.refreshedAt = time.Now()
+
+	return nil
+}
+
+// ServeHTTP is http.Handler.ServeHTTP
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(
+		b,
+		"# HELP pullkee_pull_requests Number of analyzed pull requests\n"+
+			"# TYPE pullkee_pull_requests gauge\n"+
+			"pullkee_pull_requests{repo=%q} %d\n"+
+			"# HELP pullkee_last_refresh_timestamp_seconds When the metrics were calculated\n"+
+			"# TYPE pullkee_last_refresh_timestamp_seconds gauge\n"+
+			"pullkee_last_refresh_timestamp_seconds{repo=%q} %d\n",
+		s.repo,
+		s.pulls,
+		s.repo,
+		s.refreshedAt.Unix(),
+	)
+
+	if err := report.Prometheus(s.repo)(b, s.entries); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(b.Bytes())
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"time"
@@ -23,14 +24,16 @@ func (c abusePreventing) Do(req *http.Request) (*http.Response, error) {
 	retryAfter, parseError := strconv.ParseFloat(res.Header.Get("Retry-After"), 64)
 
 	if is403 && parseError == nil {
-		c.waitFor(retryAfter)
+		if err := c.waitFor(req.Context(), retryAfter); err != nil {
+			return nil, err
+		}
+		rewind(req)
 		res, err = c.Do(req)
 	}
 	return res, err
 }
 
-func (c abusePreventing) waitFor(seconds float64) {
-	duration := time.Duration(seconds * float64(time.Second))
-	timer := time.NewTimer(duration)
-	<-timer.C
+// waitFor sleeps for the given number of seconds unless `ctx` is cancelled earlier
+func (c abusePreventing) waitFor(ctx context.Context, seconds float64) error {
+	return sleep(ctx, time.Duration(seconds*float64(time.Second)))
 }
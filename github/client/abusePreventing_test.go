@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -176,4 +177,34 @@ func TestAbusePreventing(t *testing.T) {
 		require.Equal(t, "no", res.Header.Get("Success"))
 		require.Equal(t, 1, timesCalled)
 	})
+
+	t.Run("Stops waiting when the context is cancelled", func(t *testing.T) {
+		timesCalled := 0
+
+		response := func() (*http.Response, error) {
+			defer func() { timesCalled++ }()
+			return &http.Response{
+				StatusCode: 403,
+				Header: http.Header{
+					"Retry-After": []string{"10"},
+				},
+			}, nil
+		}
+
+		client := abusePreventing{
+			HTTPClient: httpClientMock{response},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+
+		t1 := time.Now()
+		res, err := client.Do(dummyRequest().WithContext(ctx))
+		secondsPassed := time.Now().Sub(t1).Seconds()
+
+		require.Nil(t, res)
+		require.Equal(t, context.DeadlineExceeded, err)
+		require.Equal(t, 1, timesCalled)
+		require.True(t, secondsPassed < 0.1, fmt.Sprintf("Should stop waiting after 50ms, passed %f", secondsPassed))
+	})
 }
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AppCredentials identify an installation of a Github App to act on behalf of
+// https://developer.github.com/apps/building-github-apps/authenticating-with-github-apps/
+type AppCredentials struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKey     *rsa.PrivateKey
+	// BaseURL is the root of the API to request the installation tokens from, https://api.github.com if empty
+	BaseURL string
+}
+
+// ParsePrivateKey parses a PEM-encoded RSA private key as it's downloaded from the settings of the App
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("Expected a PEM-encoded private key")
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("Expected an RSA private key")
+	}
+	return rsaKey, nil
+}
+
+// tokenRefreshMargin is how long before the expiry an installation token is replaced by a new one
+const tokenRefreshMargin = 5 * time.Minute
+
+// appAuthenticating is a HTTPClient which authenticates the queries as an installation of a Github App.
+// It exchanges a JWT signed by the App's private key for an installation token and keeps it until it's about to expire
+type appAuthenticating struct {
+	HTTPClient // "back-end" HTTPClient to use for actual HTTP queries and the token exchange
+	*AppCredentials
+	*installationToken // shared between all the copies of the client
+}
+
+type installationToken struct {
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// Do is HTTPClient.Do
+func (c appAuthenticating) Do(req *http.Request) (*http.Response, error) {
+	token, err := c.token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	Credentials{Token: token}.authorize(req)
+	return c.HTTPClient.Do(req)
+}
+
+// token returns the current installation token, getting a new one if needed.
+// The concurrent queries wait for a single exchange instead of making their own
+func (c appAuthenticating) token(ctx context.Context) (string, error) {
+	c.installationToken.mu.Lock()
+	defer c.installationToken.mu.Unlock()
+
+	if c.installationToken.token != "" && time.Now().Add(tokenRefreshMargin).Before(c.installationToken.expiresAt) {
+		return c.installationToken.token, nil
+	}
+
+	token, expiresAt, err := c.exchange(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.installationToken.token = token
+	c.installationToken.expiresAt = expiresAt
+	return token, nil
+}
+
+// exchange gets a new installation token
+func (c appAuthenticating) exchange(ctx context.Context) (string, time.Time, error) {
+	jwt, err := c.jwt(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimSuffix(baseURL, "/"), c.InstallationID)
+
+	req, _ := http.NewRequest("POST", url, nil)
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github.machine-man-preview+json")
+	req.Header.Set("User-Agent", "pullkee")
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if res.Body == nil {
+		return "", time.Time{}, fmt.Errorf("Expected res.Body not to be nil. URL: %s", url)
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if res.StatusCode != http.StatusCreated {
+		return "", time.Time{}, fmt.Errorf(
+			"Couldn't get an installation token, response code: %d, body:\n%s",
+			res.StatusCode,
+			body,
+		)
+	}
+
+	response := struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return response.Token, response.ExpiresAt, nil
+}
+
+// jwt composes a JSON Web Token signed by the App's private key with RS256
+func (c appAuthenticating) jwt(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]int64{
+		// a minute ago to make up for the difference between the clocks
+		"iat": now.Add(-time.Minute).Unix(),
+		// 10 minutes is the maximum
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": c.AppID,
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.PrivateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppAuthenticating(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	// tokenServer is a fake of the Github endpoint issuing installation tokens.
+	// The tokens are "token1", "token2", etc. and expire in `ttl`
+	tokenServer := func(ttl time.Duration, exchanges *int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "POST" || r.URL.Path != "/app/installations/42/access_tokens" {
+				http.Error(w, "Not found", http.StatusNotFound)
+				return
+			}
+
+			claims, err := verifyJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey)
+			if err != nil || claims["iss"] != 7 {
+				http.Error(w, "Bad credentials", http.StatusUnauthorized)
+				return
+			}
+
+			*exchanges++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(
+				w,
+				`{"token": "token%d", "expires_at": %q}`,
+				*exchanges,
+				time.Now().Add(ttl).UTC().Format(time.RFC3339),
+			)
+		}))
+	}
+
+	newClient := func(server *httptest.Server, key *rsa.PrivateKey, authorizations *[]string) appAuthenticating {
+		return appAuthenticating{
+			HTTPClient: httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				if strings.HasPrefix(req.URL.String(), server.URL) {
+					return http.DefaultClient.Do(req)
+				}
+				*authorizations = append(*authorizations, req.Header.Get("Authorization"))
+				return successfulResponse()
+			}),
+			AppCredentials: &AppCredentials{
+				AppID:          7,
+				InstallationID: 42,
+				PrivateKey:     key,
+				BaseURL:        server.URL,
+			},
+			installationToken: &installationToken{},
+		}
+	}
+
+	t.Run("Gets an installation token once and reuses it", func(t *testing.T) {
+		exchanges := 0
+		server := tokenServer(time.Hour, &exchanges)
+		defer server.Close()
+
+		authorizations := []string{}
+		client := newClient(server, key, &authorizations)
+
+		for i := 0; i < 2; i++ {
+			res, err := client.Do(dummyRequest())
+			require.Nil(t, err)
+			require.Equal(t, "yes", res.Header.Get("Success"))
+		}
+
+		require.Equal(t, 1, exchanges)
+		require.Equal(t, []string{"Bearer token1", "Bearer token1"}, authorizations)
+	})
+
+	t.Run("Gets a new token when the current one is about to expire", func(t *testing.T) {
+		exchanges := 0
+		server := tokenServer(time.Minute, &exchanges)
+		defer server.Close()
+
+		authorizations := []string{}
+		client := newClient(server, key, &authorizations)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Do(dummyRequest())
+			require.Nil(t, err)
+		}
+
+		require.Equal(t, 2, exchanges)
+		require.Equal(t, []string{"Bearer token1", "Bearer token2"}, authorizations)
+	})
+
+	t.Run("Fails when the token couldn't be obtained", func(t *testing.T) {
+		exchanges := 0
+		server := tokenServer(time.Hour, &exchanges)
+		defer server.Close()
+
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.Nil(t, err)
+
+		authorizations := []string{}
+		client := newClient(server, otherKey, &authorizations)
+
+		res, err := client.Do(dummyRequest())
+
+		require.Nil(t, res)
+		require.Contains(t, err.Error(), "Couldn't get an installation token, response code: 401")
+		require.Len(t, authorizations, 0)
+	})
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	t.Run("Parses a PKCS1 key", func(t *testing.T) {
+		data := pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})
+
+		parsed, err := ParsePrivateKey(data)
+		require.Nil(t, err)
+		require.Equal(t, key.N, parsed.N)
+	})
+
+	t.Run("Fails on something else", func(t *testing.T) {
+		_, err := ParsePrivateKey([]byte("Total rubbish"))
+		require.EqualError(t, err, "Expected a PEM-encoded private key")
+	})
+}
+
+// verifyJWT checks the RS256 signature of the token and returns its claims
+func verifyJWT(token string, key *rsa.PublicKey) (map[string]int64, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("Expected 3 parts of JWT")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]int64{}
+	return claims, json.Unmarshal(payload, &claims)
+}
@@ -2,10 +2,32 @@ package client
 
 import "net/http"
 
-// Credentials struct contains user's name and access token to access the Github API
+// Credentials struct contains what's needed to access the Github API: either user's name and
+// personal access token for the basic auth or just a token (e.g. the one of Github Actions)
 type Credentials struct {
 	Username            string
 	PersonalAccessToken string
+	Token               string // sent as "Authorization: Bearer" if set, the rest is ignored then
+}
+
+// authorize sets the authorization headers of the request according to the provided credentials
+func (c Credentials) authorize(req *http.Request) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		req.Header.Set("User-Agent", "pullkee")
+		return
+	}
+
+	req.SetBasicAuth(c.Username, c.PersonalAccessToken)
+	req.Header.Set("User-Agent", c.Username)
+}
+
+// identity is what the credentials are told apart by, it's only stored hashed
+func (c Credentials) identity() string {
+	if c.Token != "" {
+		return "token " + c.Token
+	}
+	return "basic " + c.Username + ":" + c.PersonalAccessToken
 }
 
 // authenticating is a HTTPClient which sets authorization headers for Github API
@@ -17,8 +39,7 @@ type authenticating struct {
 // Do is HTTPClient.Do
 func (c authenticating) Do(req *http.Request) (*http.Response, error) {
 	if creds := c.Credentials; creds != nil {
-		req.SetBasicAuth(creds.Username, creds.PersonalAccessToken)
-		req.Header.Add("User-Agent", creds.Username)
+		creds.authorize(req)
 	}
 	return c.HTTPClient.Do(req)
 
@@ -29,6 +29,42 @@ func TestAuth(t *testing.T) {
 		}, request.Header)
 	})
 
+	t.Run("Bearer auth header is set, when there is a Token", func(t *testing.T) {
+		request := dummyRequest()
+
+		client := authenticating{
+			HTTPClient: httpClientMock{successfulResponse},
+			Credentials: &Credentials{
+				Token: "Token1",
+			},
+		}
+
+		_, err := client.Do(request)
+
+		require.Nil(t, err)
+		require.Equal(t, http.Header{
+			"Authorization": []string{"Bearer Token1"},
+			"User-Agent":    []string{"pullkee"},
+		}, request.Header)
+	})
+
+	t.Run("Headers aren't duplicated when the request is repeated", func(t *testing.T) {
+		request := dummyRequest()
+
+		client := authenticating{
+			HTTPClient: httpClientMock{successfulResponse},
+			Credentials: &Credentials{
+				Username:            "User1",
+				PersonalAccessToken: "Token1",
+			},
+		}
+
+		client.Do(request)
+		client.Do(request)
+
+		require.Equal(t, []string{"User1"}, request.Header["User-Agent"])
+	})
+
 	t.Run("Still works when Credentials aren't present", func(t *testing.T) {
 		request := dummyRequest()
 
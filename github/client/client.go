@@ -3,9 +3,12 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/kirillrogovoy/pullkee/cache"
 )
 
 // HTTPClient is in interface for a HTTP client which "transforms"
@@ -28,9 +31,20 @@ type Client struct {
 // Options is a set of configurable options to create a whole chain of clients
 type Options struct {
 	*Credentials
+	// CredentialsPool is used instead of Credentials to rotate multiple tokens
+	CredentialsPool []Credentials
+	// App is used instead of Credentials to authenticate as an installation of a Github App
+	App         *AppCredentials
 	RateLimiter *<-chan time.Time
-	MaxRetries  int
+	// Pacing adapts the pace of the queries to the rate limit quota, disabled if nil
+	Pacing     *PacingOptions
+	MaxRetries int
+	RetryPolicy
 	Log
+	// OnQuotaExhausted is called when the queries are paused until the rate limit quota is reset
+	OnQuotaExhausted func(resetAt time.Time)
+	// Cache stores the responses to make the repeated queries conditional, disabled if nil
+	Cache cache.Cache
 }
 
 // Do is HTTPClient.Do
@@ -52,9 +66,18 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 
 // New creates a new instance of Client chaining all the clients together given Options
 func New(httpClient HTTPClient, opts Options) Client {
+	if opts.Pacing != nil {
+		httpClient = pacing{
+			HTTPClient:    httpClient,
+			PacingOptions: *opts.Pacing,
+			pace:          &pace{},
+		}
+	}
 	retrying := retrying{
-		HTTPClient: httpClient,
-		MaxRetries: opts.MaxRetries,
+		HTTPClient:  httpClient,
+		MaxRetries:  opts.MaxRetries,
+		RetryPolicy: opts.RetryPolicy,
+		Log:         opts.Log,
 	}
 	rateLimiting := rateLimiting{
 		HTTPClient:  retrying,
@@ -63,17 +86,94 @@ func New(httpClient HTTPClient, opts Options) Client {
 	abusePreventing := abusePreventing{
 		HTTPClient: rateLimiting,
 	}
-	auth := authenticating{
-		HTTPClient:  abusePreventing,
-		Credentials: opts.Credentials,
+	quotaWaiting := quotaWaiting{
+		HTTPClient: abusePreventing,
+		quota:      &quota{},
+		Margin:     time.Second,
+		OnWait:     opts.OnQuotaExhausted,
+	}
+	var auth HTTPClient
+	switch {
+	case len(opts.CredentialsPool) > 0:
+		// rotating waits for the quota itself since it's tracked per token
+		auth = rotating{
+			HTTPClient: abusePreventing,
+			pool:       newPool(opts.CredentialsPool),
+			Margin:     time.Second,
+			OnWait:     opts.OnQuotaExhausted,
+		}
+	case opts.App != nil:
+		auth = appAuthenticating{
+			HTTPClient:        quotaWaiting,
+			AppCredentials:    opts.App,
+			installationToken: &installationToken{},
+		}
+	default:
+		auth = authenticating{
+			HTTPClient:  quotaWaiting,
+			Credentials: opts.Credentials,
+		}
 	}
 	errorWrapping := errorWrapping{
 		HTTPClient: auth,
 	}
+	var top HTTPClient = errorWrapping
+	if opts.Cache != nil {
+		top = revalidating{
+			HTTPClient: errorWrapping,
+			Cache:      opts.Cache,
+			Identity:   opts.identity(),
+		}
+	}
 	client := Client{
-		HTTPClient: errorWrapping,
+		HTTPClient: top,
 		Log:        opts.Log,
 	}
 
 	return client
 }
+
+// identity tells apart the credentials the queries are made with.
+// The tokens of a pool share the responses since they are expected to belong to the same people
+func (o Options) identity() string {
+	switch {
+	case len(o.CredentialsPool) > 0:
+		identity := "pool"
+		for _, c := range o.CredentialsPool {
+			identity += "\n" + c.identity()
+		}
+		return identity
+	case o.App != nil:
+		return fmt.Sprintf("app %d, installation %d", o.App.AppID, o.App.InstallationID)
+	case o.Credentials != nil:
+		return o.Credentials.identity()
+	default:
+		return ""
+	}
+}
+
+// sleep waits for `duration` unless `ctx` is cancelled earlier
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rewind makes the body of the request readable again before repeating it
+func rewind(req *http.Request) {
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			req.Body = body
+		}
+	}
+}
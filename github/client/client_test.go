@@ -39,6 +39,26 @@ func TestNew(t *testing.T) {
 	require.Equal(t, "yes", res.Header.Get("Success"))
 }
 
+func TestOptionsIdentity(t *testing.T) {
+	alice := Credentials{Username: "alice", PersonalAccessToken: "secret"}
+	bob := Credentials{Token: "secret"}
+
+	identities := []string{
+		Options{}.identity(),
+		Options{Credentials: &alice}.identity(),
+		Options{Credentials: &bob}.identity(),
+		Options{CredentialsPool: []Credentials{alice, bob}}.identity(),
+		Options{App: &AppCredentials{AppID: 1, InstallationID: 2}}.identity(),
+		Options{App: &AppCredentials{AppID: 1, InstallationID: 3}}.identity(),
+	}
+
+	seen := map[string]bool{}
+	for _, identity := range identities {
+		require.False(t, seen[identity], identity)
+		seen[identity] = true
+	}
+}
+
 type httpClientMock struct {
 	response func() (*http.Response, error)
 }
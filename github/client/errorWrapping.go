@@ -6,7 +6,8 @@ import (
 	"net/http/httputil"
 )
 
-// errorWrapping is a HTTPClient which translates HTTP Response codes >= 300 into errors
+// errorWrapping is a HTTPClient which translates HTTP Response codes >= 300 into errors.
+// "304 Not Modified" isn't an error since it's the answer to a conditional query
 type errorWrapping struct {
 	HTTPClient // "back-end" HTTPClient to use for actual HTTP queries
 }
@@ -14,8 +15,11 @@ type errorWrapping struct {
 // Do is HTTPClient.Do
 func (c errorWrapping) Do(req *http.Request) (*http.Response, error) {
 	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
 
-	if res.StatusCode >= 300 {
+	if res.StatusCode >= 300 && res.StatusCode != http.StatusNotModified {
 		err = fmt.Errorf(
 			"Wrong HTTP response code: %d, Details:\n%s",
 			res.StatusCode,
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -25,6 +26,34 @@ func TestErrorWrapping(t *testing.T) {
 		require.Contains(t, err.Error(), "Wrong HTTP response code: 404")
 	})
 
+	t.Run("Passes the errors of the back-end client through", func(t *testing.T) {
+		client := errorWrapping{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				return nil, fmt.Errorf("Some weird network error")
+			}},
+		}
+
+		res, err := client.Do(dummyRequest())
+
+		require.Nil(t, res)
+		require.EqualError(t, err, "Some weird network error")
+	})
+
+	t.Run("Passes a 304 through", func(t *testing.T) {
+		client := errorWrapping{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 304,
+				}, nil
+			}},
+		}
+
+		res, err := client.Do(dummyRequest())
+
+		require.Nil(t, err)
+		require.Equal(t, 304, res.StatusCode)
+	})
+
 	t.Run("Still works when the response code is OK", func(t *testing.T) {
 		request := dummyRequest()
 
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PacingOptions configures the pace of the queries
+type PacingOptions struct {
+	// MaxRPS caps the number of queries per second, no limit if zero
+	MaxRPS float64
+	// Threshold is the share of the rate limit (from 0 to 1) below which the remaining quota
+	// is spread evenly until the reset. Above it, the queries are only capped by MaxRPS
+	Threshold float64
+}
+
+// pacing is a HTTPClient which adapts the pace of the queries to the rate limit quota
+// reported by the server, so that it lasts until the reset
+type pacing struct {
+	HTTPClient // "back-end" HTTPClient to use for actual HTTP queries
+	PacingOptions
+	*pace // shared between all the copies of the client
+}
+
+type pace struct {
+	mu       sync.Mutex
+	interval time.Duration // between the queries according to the quota
+	last     time.Time     // when the last query was started
+}
+
+// Do is HTTPClient.Do
+func (c pacing) Do(req *http.Request) (*http.Response, error) {
+	if err := c.wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.adjust(res)
+	return res, nil
+}
+
+// wait takes the next free slot and blocks until it comes unless `ctx` is cancelled earlier
+func (c pacing) wait(ctx context.Context) error {
+	c.pace.mu.Lock()
+	interval := c.pace.interval
+	if c.MaxRPS > 0 {
+		if min := time.Duration(float64(time.Second) / c.MaxRPS); interval < min {
+			interval = min
+		}
+	}
+
+	now := time.Now()
+	start := c.pace.last.Add(interval)
+	if start.Before(now) {
+		start = now
+	}
+	c.pace.last = start
+	c.pace.mu.Unlock()
+
+	return sleep(ctx, start.Sub(now))
+}
+
+// adjust updates the interval between the queries given the quota in the response headers
+func (c pacing) adjust(res *http.Response) {
+	limit, err := strconv.Atoi(res.Header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	interval := time.Duration(0)
+	// an exhausted quota is up to quotaWaiting
+	if remaining > 0 && float64(remaining) < c.Threshold*float64(limit) {
+		interval = time.Unix(reset, 0).Sub(time.Now()) / time.Duration(remaining)
+	}
+	if interval < 0 {
+		interval = 0
+	}
+
+	c.pace.mu.Lock()
+	c.pace.interval = interval
+	c.pace.mu.Unlock()
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacing(t *testing.T) {
+	quotaResponse := func(remaining int, resetIn time.Duration) func() (*http.Response, error) {
+		return func() (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Header: http.Header{
+					"X-Ratelimit-Limit":     []string{"100"},
+					"X-Ratelimit-Remaining": []string{strconv.Itoa(remaining)},
+					"X-Ratelimit-Reset":     []string{strconv.FormatInt(time.Now().Add(resetIn).Unix(), 10)},
+				},
+			}, nil
+		}
+	}
+
+	timeOf := func(client pacing, times int) float64 {
+		t1 := time.Now()
+		for i := 0; i < times; i++ {
+			_, err := client.Do(dummyRequest())
+			require.Nil(t, err)
+		}
+		return time.Now().Sub(t1).Seconds()
+	}
+
+	t.Run("Spreads the queries once the quota drops below the threshold", func(t *testing.T) {
+		// 10 queries left for 1-2 seconds (the reset is rounded down to seconds) give 100-200ms between them
+		client := pacing{
+			HTTPClient:    httpClientMock{quotaResponse(10, time.Second*2)},
+			PacingOptions: PacingOptions{Threshold: 0.5},
+			pace:          &pace{},
+		}
+
+		secondsPassed := timeOf(client, 2)
+		require.True(
+			t,
+			secondsPassed >= 0.1 && secondsPassed < 0.25,
+			fmt.Sprintf("100-200ms should pass because of pacing, passed %f", secondsPassed),
+		)
+	})
+
+	t.Run("Doesn't slow down while the quota is above the threshold", func(t *testing.T) {
+		client := pacing{
+			HTTPClient:    httpClientMock{quotaResponse(90, time.Second*2)},
+			PacingOptions: PacingOptions{Threshold: 0.5},
+			pace:          &pace{},
+		}
+
+		secondsPassed := timeOf(client, 5)
+		require.True(t, secondsPassed < 0.05, fmt.Sprintf("Shouldn't wait, passed %f", secondsPassed))
+	})
+
+	t.Run("Caps the queries per second", func(t *testing.T) {
+		client := pacing{
+			HTTPClient:    httpClientMock{successfulResponse},
+			PacingOptions: PacingOptions{MaxRPS: 20},
+			pace:          &pace{},
+		}
+
+		secondsPassed := timeOf(client, 3)
+		require.True(
+			t,
+			secondsPassed >= 0.1 && secondsPassed < 0.15,
+			fmt.Sprintf("100ms should pass because of MaxRPS, passed %f", secondsPassed),
+		)
+	})
+
+	t.Run("Fails right away if there was an error", func(t *testing.T) {
+		client := pacing{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				return nil, fmt.Errorf("Some weird network error")
+			}},
+			pace: &pace{},
+		}
+
+		res, err := client.Do(dummyRequest())
+
+		require.Nil(t, res)
+		require.EqualError(t, err, "Some weird network error")
+	})
+
+	t.Run("Stops waiting when the context is cancelled", func(t *testing.T) {
+		client := pacing{
+			HTTPClient:    httpClientMock{successfulResponse},
+			PacingOptions: PacingOptions{MaxRPS: 0.1},
+			pace:          &pace{},
+		}
+
+		_, err := client.Do(dummyRequest())
+		require.Nil(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+
+		res, err := client.Do(dummyRequest().WithContext(ctx))
+		require.Nil(t, res)
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+}
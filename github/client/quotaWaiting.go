@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// quotaWaiting is a HTTPClient which pauses all the queries once the rate limit quota is exhausted
+// and resumes them after the reset. A query rejected because of the exhausted quota is repeated
+// https://developer.github.com/v3/#rate-limiting
+type quotaWaiting struct {
+	HTTPClient // "back-end" HTTPClient to use for actual HTTP queries
+	*quota     // shared between all the copies of the client
+	// Margin is added to the reset time to make up for the difference between the clocks
+	Margin time.Duration
+	// OnWait is called once every time the quota gets exhausted
+	OnWait func(until time.Time)
+}
+
+type quota struct {
+	mu      sync.Mutex
+	resetAt time.Time
+}
+
+// Do is HTTPClient.Do
+func (c quotaWaiting) Do(req *http.Request) (*http.Response, error) {
+	for {
+		if err := c.wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		res, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		resetAt, exhausted := quotaReset(res)
+		if !exhausted {
+			return res, nil
+		}
+		c.exhaust(resetAt)
+
+		if !quotaRejected(res) {
+			return res, nil
+		}
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		rewind(req)
+	}
+}
+
+// wait blocks until the quota is reset unless `ctx` is cancelled earlier
+func (c quotaWaiting) wait(ctx context.Context) error {
+	c.quota.mu.Lock()
+	duration := c.quota.resetAt.Sub(time.Now())
+	c.quota.mu.Unlock()
+
+	return sleep(ctx, duration)
+}
+
+// exhaust makes all the following queries wait until `resetAt`
+func (c quotaWaiting) exhaust(resetAt time.Time) {
+	// the reset time might be already in the past according to our clock
+	if now := time.Now(); resetAt.Before(now) {
+		resetAt = now
+	}
+	resetAt = resetAt.Add(c.Margin)
+
+	c.quota.mu.Lock()
+	extended := resetAt.After(c.quota.resetAt)
+	if extended {
+		c.quota.resetAt = resetAt
+	}
+	c.quota.mu.Unlock()
+
+	if extended && c.OnWait != nil {
+		c.OnWait(resetAt)
+	}
+}
+
+// quotaRejected tells if the query was rejected because the quota is exhausted.
+// Github responds with either 403 or 429 in that case
+func quotaRejected(res *http.Response) bool {
+	return (res.StatusCode == 403 || res.StatusCode == 429) && res.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// quotaReset tells when the quota is reset if it's exhausted according to the response
+func quotaReset(res *http.Response) (time.Time, bool) {
+	if res.Header.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false
+	}
+
+	reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(reset, 0), true
+}
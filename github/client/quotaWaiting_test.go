@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaWaiting(t *testing.T) {
+	// the reset time is in the past, so the client only waits for the margin
+	exhaustedHeader := func() http.Header {
+		return http.Header{
+			"X-Ratelimit-Remaining": []string{"0"},
+			"X-Ratelimit-Reset":     []string{strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)},
+			"Success":               []string{"no"},
+		}
+	}
+
+	// Github rejects the queries over the quota with either 403 or 429
+	for _, code := range []int{403, 429} {
+		code := code
+		t.Run(fmt.Sprintf("Waits for the reset and repeats the query rejected with %d because of the quota", code), func(t *testing.T) {
+			timesCalled := 0
+
+			response := func() (*http.Response, error) {
+				defer func() { timesCalled++ }()
+				switch timesCalled {
+				case 0:
+					return &http.Response{
+						StatusCode: code,
+						Header:     exhaustedHeader(),
+					}, nil
+				case 1:
+					return successfulResponse()
+				default:
+					panic("Should not be called")
+				}
+			}
+
+			waits := 0
+			client := quotaWaiting{
+				HTTPClient: httpClientMock{response},
+				quota:      &quota{},
+				Margin:     time.Millisecond * 100,
+				OnWait:     func(until time.Time) { waits++ },
+			}
+
+			t1 := time.Now()
+			res, err := client.Do(dummyRequest())
+			secondsPassed := time.Now().Sub(t1).Seconds()
+
+			require.Nil(t, err)
+			require.Equal(t, "yes", res.Header.Get("Success"))
+			require.Equal(t, 2, timesCalled)
+			require.Equal(t, 1, waits)
+			require.True(
+				t,
+				secondsPassed >= 0.1 && secondsPassed < 0.2,
+				fmt.Sprintf("100ms should pass because of the exhausted quota, passed %f", secondsPassed),
+			)
+		})
+	}
+
+	t.Run("Pauses the following queries once the last one of the quota is used", func(t *testing.T) {
+		timesCalled := 0
+
+		response := func() (*http.Response, error) {
+			defer func() { timesCalled++ }()
+			switch timesCalled {
+			case 0:
+				return &http.Response{
+					StatusCode: 200,
+					Header:     exhaustedHeader(),
+				}, nil
+			case 1:
+				return successfulResponse()
+			default:
+				panic("Should not be called")
+			}
+		}
+
+		client := quotaWaiting{
+			HTTPClient: httpClientMock{response},
+			quota:      &quota{},
+			Margin:     time.Millisecond * 100,
+		}
+
+		t1 := time.Now()
+
+		res, err := client.Do(dummyRequest())
+		require.Nil(t, err)
+		require.Equal(t, "no", res.Header.Get("Success"))
+
+		// a copy shares the quota with the original
+		copied := client
+		res, err = copied.Do(dummyRequest())
+		require.Nil(t, err)
+		require.Equal(t, "yes", res.Header.Get("Success"))
+
+		secondsPassed := time.Now().Sub(t1).Seconds()
+		require.True(
+			t,
+			secondsPassed >= 0.1 && secondsPassed < 0.2,
+			fmt.Sprintf("100ms should pass because of the exhausted quota, passed %f", secondsPassed),
+		)
+	})
+
+	t.Run("Doesn't wait when there is quota left", func(t *testing.T) {
+		client := quotaWaiting{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 403,
+					Header: http.Header{
+						"X-Ratelimit-Remaining": []string{"10"},
+						"X-Ratelimit-Reset":     []string{"1"},
+					},
+				}, nil
+			}},
+			quota: &quota{},
+			OnWait: func(until time.Time) {
+				panic("Should not be called")
+			},
+		}
+
+		res, err := client.Do(dummyRequest())
+
+		require.Nil(t, err)
+		require.Equal(t, 403, res.StatusCode)
+	})
+
+	t.Run("Fails right away if there was an error", func(t *testing.T) {
+		client := quotaWaiting{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				return nil, fmt.Errorf("Some weird network error")
+			}},
+			quota: &quota{},
+		}
+
+		res, err := client.Do(dummyRequest())
+
+		require.Nil(t, res)
+		require.EqualError(t, err, "Some weird network error")
+	})
+
+	t.Run("Stops waiting when the context is cancelled", func(t *testing.T) {
+		client := quotaWaiting{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 403,
+					Header:     exhaustedHeader(),
+				}, nil
+			}},
+			quota:  &quota{},
+			Margin: time.Second * 10,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+
+		res, err := client.Do(dummyRequest().WithContext(ctx))
+
+		require.Nil(t, res)
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+}
@@ -15,7 +15,11 @@ type rateLimiting struct {
 // Do is HTTPClient.Do
 func (c rateLimiting) Do(req *http.Request) (*http.Response, error) {
 	if c.RateLimiter != nil {
-		<-*c.RateLimiter
+		select {
+		case <-*c.RateLimiter:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
 	}
 	return c.HTTPClient.Do(req)
 
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -46,4 +47,20 @@ func TestRateLimiting(t *testing.T) {
 		require.Equal(t, "yes", res.Header.Get("Success"))
 		require.Nil(t, err)
 	})
+
+	t.Run("Stops waiting when the context is cancelled", func(t *testing.T) {
+		limiter := make(<-chan time.Time)
+
+		client := rateLimiting{
+			HTTPClient:  httpClientMock{successfulResponse},
+			RateLimiter: &limiter,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		res, err := client.Do(dummyRequest().WithContext(ctx))
+		require.Nil(t, res)
+		require.Equal(t, context.Canceled, err)
+	})
 }
@@ -1,22 +1,140 @@
 package client
 
-import "net/http"
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures which failed queries are re-tried and how long to wait in between.
+// The zero value re-tries only on network errors and without waiting
+type RetryPolicy struct {
+	// StatusCodes are the response codes which are worth retrying (e.g. 502)
+	StatusCodes []int
+	// BaseDelay is the delay before the first retry. It's doubled after each one (with a random jitter)
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries, no limit if zero
+	MaxDelay time.Duration
+	// MaxElapsed stops retrying once that much time has passed since the first try, no limit if zero
+	MaxElapsed time.Duration
+}
+
+// DefaultRetryPolicy re-tries the server errors and the secondary rate limits with an exponential backoff
+var DefaultRetryPolicy = RetryPolicy{
+	StatusCodes: []int{500, 502, 503, 504, 429},
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+	MaxElapsed:  2 * time.Minute,
+}
 
 // retrying is a HTTPClient which re-tries the request for a number of times in case of network errors
+// or retryable response codes according to RetryPolicy.
+// It gives up as soon as the request's context is done
 type retrying struct {
 	HTTPClient // "back-end" HTTPClient to use for actual HTTP queries
 	MaxRetries int
+	RetryPolicy
+	Log
 }
 
 // Do is HTTPClient.Do
 func (c retrying) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
 	res, err := c.HTTPClient.Do(req)
 
-	retriesLeft := c.MaxRetries
-	for err != nil && retriesLeft > 0 {
-		retriesLeft--
+	for try := 1; try <= c.MaxRetries && c.retryable(res, err); try++ {
+		delay := c.delay(try, res)
+		if c.MaxElapsed > 0 && time.Since(start)+delay > c.MaxElapsed {
+			break
+		}
+		if req.Context().Err() != nil {
+			break
+		}
+
+		if c.Log != nil {
+			c.Log(fmt.Sprintf(
+				"RETRY %d/%d in %s - %s: %s (%s)",
+				try,
+				c.MaxRetries,
+				delay,
+				req.Method,
+				req.URL.String(),
+				failure(res, err),
+			))
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		if res != nil && res.Body != nil {
+			res.Body.Close()
+		}
+		rewind(req)
 		res, err = c.HTTPClient.Do(req)
 	}
 
 	return res, err
 }
+
+func (c retrying) retryable(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	// nothing succeeds before the reset, it's up to quotaWaiting or rotating to wait for it
+	if quotaRejected(res) {
+		return false
+	}
+
+	for _, code := range c.StatusCodes {
+		if res.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// delay tells how long to wait before the `try`-th retry (starting from 1)
+func (c retrying) delay(try int, res *http.Response) time.Duration {
+	if res != nil && res.StatusCode == 429 {
+		if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return retryAfter
+		}
+	}
+
+	if c.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := c.BaseDelay
+	for i := 1; i < try && (c.MaxDelay <= 0 || delay < c.MaxDelay); i++ {
+		delay *= 2
+	}
+	if c.MaxDelay > 0 && delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+
+	// "equal jitter": a half of the delay is fixed and another half is random
+	half := int64(delay / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// parseRetryAfter parses the value of Retry-After which is either a number of seconds or a date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(time.Now()), true
+	}
+	return 0, false
+}
+
+func failure(res *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("HTTP %d", res.StatusCode)
+}
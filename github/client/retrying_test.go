@@ -1,9 +1,13 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -58,4 +62,197 @@ func TestRetrying(t *testing.T) {
 		require.Contains(t, err.Error(), "Some weird network error which doesn't go away, try #3")
 		require.Equal(t, 3, timesCalled, fmt.Sprintf("Should try for 3 times before giving up. Tried %d times", timesCalled))
 	})
+
+	t.Run("It doesn't retry once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		timesCalled := 0
+		response := func() (*http.Response, error) {
+			defer func() { timesCalled++ }()
+			cancel()
+			return nil, context.Canceled
+		}
+
+		client := retrying{
+			HTTPClient: httpClientMock{response},
+			MaxRetries: 2,
+		}
+
+		res, err := client.Do(dummyRequest().WithContext(ctx))
+
+		require.Nil(t, res)
+		require.Equal(t, context.Canceled, err)
+		require.Equal(t, 1, timesCalled)
+	})
+
+	t.Run("It retries on a retryable response code and logs it", func(t *testing.T) {
+		timesCalled := 0
+
+		response := func() (*http.Response, error) {
+			defer func() { timesCalled++ }()
+			switch timesCalled {
+			case 0:
+				return &http.Response{StatusCode: 502}, nil
+			case 1:
+				return successfulResponse()
+			default:
+				panic("Should not be called")
+			}
+		}
+
+		logs := []string{}
+		client := retrying{
+			HTTPClient:  httpClientMock{response},
+			MaxRetries:  2,
+			RetryPolicy: RetryPolicy{StatusCodes: []int{502}},
+			Log:         func(message string) { logs = append(logs, message) },
+		}
+
+		res, err := client.Do(dummyRequest())
+
+		require.Nil(t, err)
+		require.Equal(t, "yes", res.Header.Get("Success"))
+		require.Equal(t, 2, timesCalled)
+		require.Equal(t, []string{"RETRY 1/2 in 0s - GET: http://example.com/url1 (HTTP 502)"}, logs)
+	})
+
+	t.Run("It doesn't retry on other response codes", func(t *testing.T) {
+		timesCalled := 0
+
+		client := retrying{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				timesCalled++
+				return &http.Response{StatusCode: 404}, nil
+			}},
+			MaxRetries:  2,
+			RetryPolicy: RetryPolicy{StatusCodes: []int{502}},
+		}
+
+		res, err := client.Do(dummyRequest())
+
+		require.Nil(t, err)
+		require.Equal(t, 404, res.StatusCode)
+		require.Equal(t, 1, timesCalled)
+	})
+
+	t.Run("It doesn't retry on 429 when the quota is exhausted", func(t *testing.T) {
+		timesCalled := 0
+
+		client := retrying{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				timesCalled++
+				return &http.Response{
+					StatusCode: 429,
+					Header:     http.Header{"X-Ratelimit-Remaining": []string{"0"}},
+				}, nil
+			}},
+			MaxRetries:  2,
+			RetryPolicy: DefaultRetryPolicy,
+		}
+
+		res, err := client.Do(dummyRequest())
+
+		require.Nil(t, err)
+		require.Equal(t, 429, res.StatusCode)
+		require.Equal(t, 1, timesCalled)
+	})
+
+	t.Run("It waits for Retry-After on 429", func(t *testing.T) {
+		timesCalled := 0
+
+		response := func() (*http.Response, error) {
+			defer func() { timesCalled++ }()
+			switch timesCalled {
+			case 0:
+				return &http.Response{
+					StatusCode: 429,
+					Header:     http.Header{"Retry-After": []string{"0.1"}},
+				}, nil
+			case 1:
+				return successfulResponse()
+			default:
+				panic("Should not be called")
+			}
+		}
+
+		client := retrying{
+			HTTPClient:  httpClientMock{response},
+			MaxRetries:  2,
+			RetryPolicy: RetryPolicy{StatusCodes: []int{429}, BaseDelay: time.Second},
+		}
+
+		t1 := time.Now()
+		res, err := client.Do(dummyRequest())
+		secondsPassed := time.Now().Sub(t1).Seconds()
+
+		require.Nil(t, err)
+		require.Equal(t, "yes", res.Header.Get("Success"))
+		require.True(
+			t,
+			secondsPassed >= 0.1 && secondsPassed < 0.2,
+			fmt.Sprintf("100ms should pass because of Retry-After, passed %f", secondsPassed),
+		)
+	})
+
+	t.Run("It gives up once the next delay would exceed the max elapsed time", func(t *testing.T) {
+		timesCalled := 0
+
+		client := retrying{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				timesCalled++
+				return &http.Response{StatusCode: 502}, nil
+			}},
+			MaxRetries: 5,
+			RetryPolicy: RetryPolicy{
+				StatusCodes: []int{502},
+				BaseDelay:   time.Second * 2,
+				MaxElapsed:  time.Second,
+			},
+		}
+
+		res, err := client.Do(dummyRequest())
+
+		require.Nil(t, err)
+		require.Equal(t, 502, res.StatusCode)
+		require.Equal(t, 1, timesCalled)
+	})
+}
+
+func TestRetryingBody(t *testing.T) {
+	bodies := []string{}
+	client := retrying{
+		HTTPClient: httpClientFunc(func(req *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(req.Body)
+			bodies = append(bodies, string(body))
+			return nil, fmt.Errorf("Some weird network error")
+		}),
+		MaxRetries: 1,
+	}
+
+	req, _ := http.NewRequest("POST", "http://example.com/url1", strings.NewReader("Some body"))
+	client.Do(req)
+
+	require.Equal(t, []string{"Some body", "Some body"}, bodies)
+}
+
+func TestRetryingDelay(t *testing.T) {
+	client := retrying{
+		RetryPolicy: RetryPolicy{
+			BaseDelay: time.Millisecond * 100,
+			MaxDelay:  time.Millisecond * 300,
+		},
+	}
+
+	between := func(delay time.Duration, min, max int) bool {
+		return delay >= time.Duration(min)*time.Millisecond && delay <= time.Duration(max)*time.Millisecond
+	}
+
+	for i := 0; i < 20; i++ {
+		require.True(t, between(client.delay(1, nil), 50, 100))
+		require.True(t, between(client.delay(2, nil), 100, 200))
+		require.True(t, between(client.delay(3, nil), 150, 300))
+		require.True(t, between(client.delay(10, nil), 150, 300))
+	}
+
+	require.Equal(t, time.Duration(0), retrying{}.delay(3, nil))
 }
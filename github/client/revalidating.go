@@ -0,0 +1,122 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/kirillrogovoy/pullkee/cache"
+)
+
+// revalidating is a HTTPClient which remembers the responses to GET queries along with their ETag or Last-Modified
+// and makes the next same query conditional (If-None-Match or If-Modified-Since).
+// A "304 Not Modified" is then served from the remembered response as a "200 OK".
+// Github doesn't count the 304 responses against the rate limit.
+// The cache failures are only logged: a broken entry is treated as a missing one
+type revalidating struct {
+	HTTPClient // "back-end" HTTPClient to use for actual HTTP queries
+	cache.Cache
+	// Identity is whom the queries are made on behalf of, so a response is never served to someone else
+	Identity string
+}
+
+// storedResponse is what's remembered of a response to revalidate it later
+type storedResponse struct {
+	Header http.Header
+	Body   []byte
+}
+
+// Do is HTTPClient.Do
+func (c revalidating) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != "GET" || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return c.HTTPClient.Do(req)
+	}
+
+	key := responseKey(req, c.Identity)
+	stored := storedResponse{}
+	found, err := c.Cache.Get(key, &stored)
+	if err != nil {
+		reportCacheError("reading", err)
+		found = false
+	}
+
+	if found {
+		if etag := stored.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		} else if lastModified := stored.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotModified && found:
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return stored.response(req, res), nil
+	case res.StatusCode == http.StatusOK && (res.Header.Get("ETag") != "" || res.Header.Get("Last-Modified") != ""):
+		if res.Body == nil {
+			return res, nil
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		if err := c.Cache.Set(key, storedResponse{Header: res.Header, Body: body}); err != nil {
+			reportCacheError("storing", err)
+		}
+	}
+
+	return res, nil
+}
+
+// reportCacheError logs a failure to read or store a response, which is then fetched in full next time
+func reportCacheError(action string, err error) {
+	log.Printf("Caching a response failed while %s it, it will be fetched in full: %s\n", action, err)
+}
+
+// response composes a "200 OK" out of the stored response.
+// The fresh headers of the 304 (e.g. the rate limit ones) take precedence over the stored ones
+func (s storedResponse) response(req *http.Request, notModified *http.Response) *http.Response {
+	header := http.Header{}
+	for k, v := range s.Header {
+		header[k] = v
+	}
+	for k, v := range notModified.Header {
+		header[k] = v
+	}
+	// a 304 has no body of its own
+	header.Set("Content-Length", strconv.Itoa(len(s.Body)))
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(s.Body)),
+		ContentLength: int64(len(s.Body)),
+		Request:       req,
+	}
+}
+
+// responseKey is the cache key of the response to the query made on behalf of `identity`.
+// Accept is a part of it since Github returns different representations depending on it
+func responseKey(req *http.Request, identity string) string {
+	hash := sha1.Sum([]byte(req.URL.String() + "\n" + req.Header.Get("Accept") + "\n" + identity))
+	return fmt.Sprintf("response-%x", hash)
+}
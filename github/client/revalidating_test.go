@@ -0,0 +1,220 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevalidating(t *testing.T) {
+	// server responds with "body1", "body2", etc. unless the query has the ETag of the current body
+	server := func(etag string, queries *[]*http.Request) HTTPClient {
+		return httpClientFunc(func(req *http.Request) (*http.Response, error) {
+			*queries = append(*queries, req)
+			header := http.Header{"X-Ratelimit-Remaining": []string{fmt.Sprint(100 - len(*queries))}}
+
+			if etag != "" && req.Header.Get("If-None-Match") == etag {
+				return &http.Response{StatusCode: 304, Header: header, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+			}
+
+			if etag != "" {
+				header.Set("ETag", etag)
+			}
+			header.Set("Link", "<http://example.com/url1?page=2>; rel=\"next\"")
+			return &http.Response{
+				StatusCode: 200,
+				Header:     header,
+				Body:       ioutil.NopCloser(strings.NewReader(fmt.Sprintf("body%d", len(*queries)))),
+			}, nil
+		})
+	}
+
+	readBody := func(res *http.Response) string {
+		body, err := ioutil.ReadAll(res.Body)
+		require.Nil(t, err)
+		return string(body)
+	}
+
+	t.Run("Serves a 304 from the stored response", func(t *testing.T) {
+		queries := []*http.Request{}
+		client := revalidating{
+			HTTPClient: server(`"abc"`, &queries),
+			Cache:      jsonCacheMock{},
+		}
+
+		res, err := client.Do(dummyRequest())
+		require.Nil(t, err)
+		require.Equal(t, "body1", readBody(res))
+
+		res, err = client.Do(dummyRequest())
+		require.Nil(t, err)
+		require.Equal(t, 200, res.StatusCode)
+		require.Equal(t, "body1", readBody(res))
+		require.Equal(t, "5", res.Header.Get("Content-Length"))
+		require.Equal(t, "<http://example.com/url1?page=2>; rel=\"next\"", res.Header.Get("Link"))
+		// the rate limit headers are the fresh ones
+		require.Equal(t, "98", res.Header.Get("X-Ratelimit-Remaining"))
+
+		require.Len(t, queries, 2)
+		require.Equal(t, "", queries[0].Header.Get("If-None-Match"))
+		require.Equal(t, `"abc"`, queries[1].Header.Get("If-None-Match"))
+	})
+
+	t.Run("Uses Last-Modified when there is no ETag", func(t *testing.T) {
+		queries := []*http.Request{}
+		client := revalidating{
+			HTTPClient: httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				queries = append(queries, req)
+				return &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{"Last-Modified": []string{"Sat, 28 Apr 2018 12:00:00 GMT"}},
+					Body:       ioutil.NopCloser(strings.NewReader("body")),
+				}, nil
+			}),
+			Cache: jsonCacheMock{},
+		}
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Do(dummyRequest())
+			require.Nil(t, err)
+		}
+
+		require.Equal(t, "", queries[0].Header.Get("If-Modified-Since"))
+		require.Equal(t, "Sat, 28 Apr 2018 12:00:00 GMT", queries[1].Header.Get("If-Modified-Since"))
+	})
+
+	t.Run("Doesn't store the responses without ETag or Last-Modified", func(t *testing.T) {
+		queries := []*http.Request{}
+		cache := jsonCacheMock{}
+		client := revalidating{
+			HTTPClient: server("", &queries),
+			Cache:      cache,
+		}
+
+		for i := 1; i <= 2; i++ {
+			res, err := client.Do(dummyRequest())
+			require.Nil(t, err)
+			require.Equal(t, fmt.Sprintf("body%d", i), readBody(res))
+		}
+
+		require.Len(t, cache, 0)
+		require.Equal(t, "", queries[1].Header.Get("If-None-Match"))
+	})
+
+	t.Run("Only touches GET queries", func(t *testing.T) {
+		queries := []*http.Request{}
+		cache := jsonCacheMock{}
+		client := revalidating{
+			HTTPClient: server(`"abc"`, &queries),
+			Cache:      cache,
+		}
+
+		req, _ := http.NewRequest("HEAD", "http://example.com/url1", nil)
+		_, err := client.Do(req)
+		require.Nil(t, err)
+
+		require.Len(t, cache, 0)
+	})
+
+	t.Run("Doesn't share the responses between identities", func(t *testing.T) {
+		queries := []*http.Request{}
+		cache := jsonCacheMock{}
+		alice := revalidating{
+			HTTPClient: server(`"abc"`, &queries),
+			Cache:      cache,
+			Identity:   "token alice",
+		}
+		bob := alice
+		bob.Identity = "token bob"
+
+		_, err := alice.Do(dummyRequest())
+		require.Nil(t, err)
+		res, err := bob.Do(dummyRequest())
+		require.Nil(t, err)
+		require.Equal(t, "body2", readBody(res))
+
+		require.Len(t, cache, 2)
+		require.Equal(t, "", queries[1].Header.Get("If-None-Match"))
+	})
+
+	t.Run("Works even when the cache fails", func(t *testing.T) {
+		queries := []*http.Request{}
+		client := revalidating{
+			HTTPClient: server(`"abc"`, &queries),
+			Cache:      failingCacheMock{},
+		}
+
+		for i := 1; i <= 2; i++ {
+			res, err := client.Do(dummyRequest())
+			require.Nil(t, err)
+			require.Equal(t, fmt.Sprintf("body%d", i), readBody(res))
+		}
+
+		require.Len(t, queries, 2)
+		require.Equal(t, "", queries[1].Header.Get("If-None-Match"))
+	})
+}
+
+// jsonCacheMock is an in-memory cache.Cache which stores the values as JSON like the real one
+type jsonCacheMock map[string][]byte
+
+func (c jsonCacheMock) Set(key string, x interface{}) error {
+	data, err := json.Marshal(x)
+	c[key] = data
+	return err
+}
+
+func (c jsonCacheMock) Get(key string, x interface{}) (bool, error) {
+	data, ok := c[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, x)
+}
+
+func (c jsonCacheMock) Delete(key string) error {
+	delete(c, key)
+	return nil
+}
+
+func (c jsonCacheMock) Clear() error {
+	for key := range c {
+		delete(c, key)
+	}
+	return nil
+}
+
+func (c jsonCacheMock) Keys() ([]string, error) {
+	keys := []string{}
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+type failingCacheMock struct{}
+
+func (c failingCacheMock) Set(key string, x interface{}) error {
+	return fmt.Errorf("Cache is broken")
+}
+
+func (c failingCacheMock) Get(key string, x interface{}) (bool, error) {
+	return false, fmt.Errorf("Cache is broken")
+}
+
+func (c failingCacheMock) Delete(key string) error {
+	return fmt.Errorf("Cache is broken")
+}
+
+func (c failingCacheMock) Clear() error {
+	return fmt.Errorf("Cache is broken")
+}
+
+func (c failingCacheMock) Keys() ([]string, error) {
+	return nil, fmt.Errorf("Cache is broken")
+}
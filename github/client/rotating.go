@@ -0,0 +1,141 @@
+package client
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rotating is a HTTPClient which authenticates every query with the credentials having
+// the most rate limit quota left, so the quota of all of them adds up.
+// Once all the quotas are exhausted, it pauses the queries until the earliest reset
+type rotating struct {
+	HTTPClient // "back-end" HTTPClient to use for actual HTTP queries
+	*pool      // shared between all the copies of the client
+	// Margin is added to the reset time to make up for the difference between the clocks
+	Margin time.Duration
+	// OnWait is called once every time all the quotas get exhausted
+	OnWait func(until time.Time)
+}
+
+type pool struct {
+	mu        sync.Mutex
+	tokens    []*token
+	waitUntil time.Time
+}
+
+type token struct {
+	Credentials
+	known     bool // whether the quota has been reported by the server yet
+	remaining int
+	resetAt   time.Time
+}
+
+func newPool(credentials []Credentials) *pool {
+	p := &pool{}
+	for _, c := range credentials {
+		p.tokens = append(p.tokens, &token{Credentials: c})
+	}
+	return p
+}
+
+// Do is HTTPClient.Do
+func (c rotating) Do(req *http.Request) (*http.Response, error) {
+	for {
+		t, until := c.pick()
+		if t == nil {
+			if err := sleep(req.Context(), until.Sub(time.Now())); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		t.authorize(req)
+
+		res, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		exhausted := c.update(t, res)
+		if !exhausted || !quotaRejected(res) {
+			return res, nil
+		}
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		rewind(req)
+	}
+}
+
+// pick returns the token with the most quota left.
+// If there is none, it returns nil and the time of the earliest reset
+func (c rotating) pick() (*token, time.Time) {
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+
+	now := time.Now()
+	var best *token
+	bestRemaining := 0
+	var earliest time.Time
+
+	for _, t := range c.pool.tokens {
+		remaining := t.remaining
+		// the ones which haven't been used yet or have been reset since go first
+		if !t.known || !now.Before(t.resetAt) {
+			remaining = math.MaxInt32
+		}
+
+		if remaining > bestRemaining {
+			best, bestRemaining = t, remaining
+		}
+		if remaining <= 0 && (earliest.IsZero() || t.resetAt.Before(earliest)) {
+			earliest = t.resetAt
+		}
+	}
+
+	if best != nil {
+		// take the query into account right away for the concurrent ones to go elsewhere
+		best.remaining--
+		return best, time.Time{}
+	}
+
+	if earliest.After(c.pool.waitUntil) {
+		c.pool.waitUntil = earliest
+		if c.OnWait != nil {
+			c.OnWait(earliest)
+		}
+	}
+	return nil, earliest
+}
+
+// update saves the quota of the token reported in the response and tells if it's exhausted
+func (c rotating) update(t *token, res *http.Response) bool {
+	remaining, err := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return false
+	}
+	reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	resetAt := time.Unix(reset, 0)
+	if remaining == 0 {
+		// the reset time might be already in the past according to our clock
+		if now := time.Now(); resetAt.Before(now) {
+			resetAt = now
+		}
+		resetAt = resetAt.Add(c.Margin)
+	}
+
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	t.known = true
+	t.remaining = remaining
+	t.resetAt = resetAt
+
+	return remaining == 0
+}
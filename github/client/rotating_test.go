@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotating(t *testing.T) {
+	quotaResponse := func(status int, remaining int, resetAt time.Time) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Header: http.Header{
+				"X-Ratelimit-Remaining": []string{strconv.Itoa(remaining)},
+				"X-Ratelimit-Reset":     []string{strconv.FormatInt(resetAt.Unix(), 10)},
+			},
+		}
+	}
+
+	credentials := []Credentials{
+		{Username: "a", PersonalAccessToken: "tokenA"},
+		{Username: "b", PersonalAccessToken: "tokenB"},
+	}
+
+	t.Run("Picks the credentials with the most quota left", func(t *testing.T) {
+		users := []string{}
+		remaining := map[string]int{"a": 10, "b": 20}
+
+		client := rotating{
+			HTTPClient: httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				user, _, _ := req.BasicAuth()
+				users = append(users, user)
+				return quotaResponse(200, remaining[user], time.Now().Add(time.Hour)), nil
+			}),
+			pool: newPool(credentials),
+		}
+
+		for i := 0; i < 3; i++ {
+			_, err := client.Do(dummyRequest())
+			require.Nil(t, err)
+		}
+
+		require.Equal(t, []string{"a", "b", "b"}, users)
+	})
+
+	t.Run("Repeats the query with other credentials when the quota is exhausted", func(t *testing.T) {
+		users := []string{}
+
+		client := rotating{
+			HTTPClient: httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				user, _, _ := req.BasicAuth()
+				users = append(users, user)
+				if user == "a" {
+					return quotaResponse(403, 0, time.Now().Add(time.Hour)), nil
+				}
+				return quotaResponse(200, 100, time.Now().Add(time.Hour)), nil
+			}),
+			pool: newPool(credentials),
+		}
+
+		res, err := client.Do(dummyRequest())
+		require.Nil(t, err)
+		require.Equal(t, 200, res.StatusCode)
+
+		res, err = client.Do(dummyRequest())
+		require.Nil(t, err)
+		require.Equal(t, 200, res.StatusCode)
+
+		require.Equal(t, []string{"a", "b", "b"}, users)
+	})
+
+	t.Run("Waits for the earliest reset once all the quotas are exhausted", func(t *testing.T) {
+		users := []string{}
+
+		client := rotating{
+			HTTPClient: httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				user, _, _ := req.BasicAuth()
+				users = append(users, user)
+				if len(users) <= 2 {
+					// the reset time is in the past, so the client only waits for the margin
+					return quotaResponse(403, 0, time.Now().Add(-time.Minute)), nil
+				}
+				return quotaResponse(200, 100, time.Now().Add(time.Hour)), nil
+			}),
+			pool:   newPool(credentials),
+			Margin: time.Millisecond * 100,
+		}
+		waits := 0
+		client.OnWait = func(until time.Time) { waits++ }
+
+		t1 := time.Now()
+		res, err := client.Do(dummyRequest())
+		secondsPassed := time.Now().Sub(t1).Seconds()
+
+		require.Nil(t, err)
+		require.Equal(t, 200, res.StatusCode)
+		require.Equal(t, []string{"a", "b", "a"}, users)
+		require.Equal(t, 1, waits)
+		require.True(
+			t,
+			secondsPassed >= 0.1 && secondsPassed < 0.2,
+			fmt.Sprintf("100ms should pass because of the exhausted quotas, passed %f", secondsPassed),
+		)
+	})
+
+	t.Run("Stops waiting when the context is cancelled", func(t *testing.T) {
+		client := rotating{
+			HTTPClient: httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				return quotaResponse(403, 0, time.Now().Add(time.Hour)), nil
+			}),
+			pool: newPool(credentials),
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+
+		res, err := client.Do(dummyRequest().WithContext(ctx))
+		require.Nil(t, res)
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+}
+
+type httpClientFunc func(req *http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -1,28 +1,30 @@
 package github
 
 import (
-	"fmt"
+	"context"
 	"math"
 	"net/http"
+	"time"
 
 	"github.com/kirillrogovoy/pullkee/github/page"
 )
 
 // Comment is a representation of a Github issue comment
 type Comment struct {
-	User User   `json:"user"`
-	Body string `json:"body"`
+	User      User      `json:"user"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // Comments fetches all the comments of a Pull Request given its `number`
-func (a APIv3) Comments(number int) ([]Comment, error) {
+func (a APIv3) Comments(ctx context.Context, number int) ([]Comment, error) {
 	allComments := []Comment{}
 
 	types := []string{"pulls", "issues"}
 	for _, commentType := range types {
 		comments := []Comment{}
-		url := fmt.Sprintf(
-			"https://api.github.com/repos/%s/%s/%d/comments?per_page=100",
+		url := a.url(
+			"/repos/%s/%s/%d/comments?per_page=100",
 			a.RepoName,
 			commentType,
 			number,
@@ -30,7 +32,7 @@ func (a APIv3) Comments(number int) ([]Comment, error) {
 		req, _ := http.NewRequest("GET", url, nil)
 
 		pageLimit := int(math.Inf(1))
-		if err := page.All(a.HTTPClient, *req, &comments, pageLimit); err != nil {
+		if err := page.All(ctx, a.HTTPClient, *req, &comments, pageLimit); err != nil {
 			return nil, err
 		}
 		allComments = append(allComments, comments...)
@@ -1,11 +1,13 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -19,7 +21,7 @@ func TestComments(t *testing.T) {
 			defer func() { timesCalled++ }()
 			switch timesCalled {
 			case 0:
-				json := `[{"user": {"login": "User1"}, "body": "Body1"}]`
+				json := `[{"user": {"login": "User1"}, "body": "Body1", "created_at": "2018-04-01T00:00:00Z"}]`
 				return &http.Response{
 					StatusCode: 200,
 					Body:       ioutil.NopCloser(strings.NewReader(json)),
@@ -28,13 +30,13 @@ func TestComments(t *testing.T) {
 					},
 				}, nil
 			case 1:
-				json := `[{"user": {"login": "User2"}, "body": "Body2"}]`
+				json := `[{"user": {"login": "User2"}, "body": "Body2", "created_at": "2018-04-02T00:00:00Z"}]`
 				return &http.Response{
 					StatusCode: 200,
 					Body:       ioutil.NopCloser(strings.NewReader(json)),
 				}, nil
 			case 2:
-				json := `[{"user": {"login": "User3"}, "body": "Body3"}]`
+				json := `[{"user": {"login": "User3"}, "body": "Body3", "created_at": "2018-04-03T00:00:00Z"}]`
 				return &http.Response{
 					StatusCode: 200,
 					Body:       ioutil.NopCloser(strings.NewReader(json)),
@@ -43,7 +45,7 @@ func TestComments(t *testing.T) {
 					},
 				}, nil
 			case 3:
-				json := `[{"user": {"login": "User4"}, "body": "Body4"}]`
+				json := `[{"user": {"login": "User4"}, "body": "Body4", "created_at": "2018-04-04T00:00:00Z"}]`
 				return &http.Response{
 					StatusCode: 200,
 					Body:       ioutil.NopCloser(strings.NewReader(json)),
@@ -58,13 +60,13 @@ func TestComments(t *testing.T) {
 		}
 
 		expected := []Comment{
-			{User{"User1"}, "Body1"},
-			{User{"User2"}, "Body2"},
-			{User{"User3"}, "Body3"},
-			{User{"User4"}, "Body4"},
+			{User{"User1"}, "Body1", time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC)},
+			{User{"User2"}, "Body2", time.Date(2018, 4, 2, 0, 0, 0, 0, time.UTC)},
+			{User{"User3"}, "Body3", time.Date(2018, 4, 3, 0, 0, 0, 0, time.UTC)},
+			{User{"User4"}, "Body4", time.Date(2018, 4, 4, 0, 0, 0, 0, time.UTC)},
 		}
 
-		comments, err := a.Comments(1)
+		comments, err := a.Comments(context.Background(), 1)
 		require.Nil(t, err)
 		require.Equal(t, expected, comments)
 	})
@@ -77,7 +79,7 @@ func TestComments(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		comments, err := a.Comments(1)
+		comments, err := a.Comments(context.Background(), 1)
 		require.EqualError(t, err, "Dogs have chewed the wires")
 		require.Nil(t, comments)
 	})
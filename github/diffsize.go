@@ -1,14 +1,16 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 )
 
 // DiffSize fetches the size of the diff of the particular Pull Request given `number`
-func (a APIv3) DiffSize(number int) (int, error) {
-	req, _ := http.NewRequest("HEAD", fmt.Sprintf("https://api.github.com/repos/%s/pulls/%d", a.RepoName, number), nil)
+func (a APIv3) DiffSize(ctx context.Context, number int) (int, error) {
+	req, _ := http.NewRequest("HEAD", a.url("/repos/%s/pulls/%d", a.RepoName, number), nil)
+	req = req.WithContext(ctx)
 	req.Header.Add("Accept", "application/vnd.github.diff")
 	res, err := a.HTTPClient.Do(req)
 	if err != nil {
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -22,7 +23,7 @@ func TestDiffsize(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		size, err := a.DiffSize(1)
+		size, err := a.DiffSize(context.Background(), 1)
 		require.Nil(t, err)
 		require.Equal(t, 42, size)
 	})
@@ -38,7 +39,7 @@ func TestDiffsize(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		_, err := a.DiffSize(1)
+		_, err := a.DiffSize(context.Background(), 1)
 		require.EqualError(t, err, "Expected Content-Length in response")
 	})
 
@@ -50,7 +51,7 @@ func TestDiffsize(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		_, err := a.DiffSize(1)
+		_, err := a.DiffSize(context.Background(), 1)
 		require.EqualError(t, err, "Some weird network error")
 	})
 }
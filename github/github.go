@@ -2,28 +2,46 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/kirillrogovoy/pullkee/github/client"
 )
 
 // API is an interface for a collection of methods to retrieve information from Github API
 type API interface {
-	Get(url string, target interface{}) error
-	Repository() (*Repository, error)
-	ClosedPullRequests(limit int) ([]PullRequest, error)
-	DiffSize(number int) (int, error)
-	Comments(number int) ([]Comment, error)
-	ReviewRequests(number int) ([]User, error)
+	Get(ctx context.Context, url string, target interface{}) error
+	Repository(ctx context.Context) (*Repository, error)
+	PullRequests(ctx context.Context, opts PullRequestsOptions) ([]PullRequest, error)
+	DiffSize(ctx context.Context, number int) (int, error)
+	Comments(ctx context.Context, number int) ([]Comment, error)
+	ReviewRequests(ctx context.Context, number int) ([]User, error)
+	Reviews(ctx context.Context, number int) ([]Review, error)
 }
 
+// DefaultBaseURL is the root of the public Github API
+const DefaultBaseURL = "https://api.github.com"
+
 // APIv3 is an implementation of API which works with Github REST API (v3)
 type APIv3 struct {
 	HTTPClient client.HTTPClient
 	RepoName   string
+	// BaseURL is the root of the API, e.g. "https://github.example.com/api/v3" for Github Enterprise.
+	// DefaultBaseURL is used if it's empty
+	BaseURL string
+}
+
+// url composes an absolute URL of the API given the path formatted with `args`
+func (a APIv3) url(format string, args ...interface{}) string {
+	baseURL := a.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + fmt.Sprintf(format, args...)
 }
 
 // User is a representation of a Github user (e.g. an author of a Pull Request)
@@ -32,9 +50,10 @@ type User struct {
 }
 
 // Get makes an HTTP request, checks the response, reads the body and unmarshals it to the `target`
-func (a APIv3) Get(url string, target interface{}) error {
+func (a APIv3) Get(ctx context.Context, url string, target interface{}) error {
 	// According to the tests of http.Request an error might only occur on an invalid method which is not the case
 	req, _ := http.NewRequest("GET", url, nil)
+	req = req.WithContext(ctx)
 
 	res, err := a.HTTPClient.Do(req)
 	if err != nil {
@@ -1,9 +1,11 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -23,7 +25,7 @@ func TestGet(t *testing.T) {
 		}
 
 		repo := &Repository{}
-		err := a.Get("/some-url", repo)
+		err := a.Get(context.Background(), "/some-url", repo)
 		require.Nil(t, err)
 		require.Equal(t, "someuser/somerepo", repo.FullName)
 	})
@@ -36,7 +38,7 @@ func TestGet(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		err := a.Get("/some-url", nil)
+		err := a.Get(context.Background(), "/some-url", nil)
 		require.Equal(t, "Some weird network error", err.Error())
 	})
 
@@ -51,7 +53,7 @@ func TestGet(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		err := a.Get("/some-url", nil)
+		err := a.Get(context.Background(), "/some-url", nil)
 		require.NotNil(t, err)
 	})
 
@@ -66,7 +68,7 @@ func TestGet(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		err := a.Get("/some-url", nil)
+		err := a.Get(context.Background(), "/some-url", nil)
 		require.Equal(t, "Some weird reader error", err.Error())
 	})
 
@@ -81,11 +83,51 @@ func TestGet(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		err := a.Get("/some-url", nil)
+		err := a.Get(context.Background(), "/some-url", nil)
 		require.Contains(t, err.Error(), "invalid character 'I'")
 	})
 }
 
+func TestBaseURL(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/api/v3/repos/someuser/somerepo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"full_name": "someuser/somerepo"}`)
+	})
+	mux.HandleFunc("/api/v3/repos/someuser/somerepo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/repos/someuser/somerepo/pulls?page=2>; rel="next"`, server.URL))
+			fmt.Fprint(w, `[{"number": 2}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"number": 1}]`)
+	})
+	mux.HandleFunc("/api/v3/repos/someuser/somerepo/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+	})
+
+	a := APIv3{
+		HTTPClient: http.DefaultClient,
+		RepoName:   "someuser/somerepo",
+		BaseURL:    server.URL + "/api/v3/",
+	}
+
+	repo, err := a.Repository(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "someuser/somerepo", repo.FullName)
+
+	pulls, err := a.PullRequests(context.Background(), PullRequestsOptions{State: StateClosed})
+	require.Nil(t, err)
+	require.Len(t, pulls, 2)
+	require.Equal(t, 1, pulls[1].Number)
+
+	size, err := a.DiffSize(context.Background(), 1)
+	require.Nil(t, err)
+	require.Equal(t, 100, size)
+}
+
 type httpClientMock struct {
 	response func() (*http.Response, error)
 }
@@ -94,6 +136,26 @@ func (h httpClientMock) Do(request *http.Request) (*http.Response, error) {
 	return h.response()
 }
 
+// httpClientFunc is a HTTPClient which also gets to see the request
+type httpClientFunc func(request *http.Request) (*http.Response, error)
+
+func (h httpClientFunc) Do(request *http.Request) (*http.Response, error) {
+	return h(request)
+}
+
+// requestRecorder responds with an empty JSON array and remembers the requested URLs
+type requestRecorder struct {
+	urls *[]string
+}
+
+func (r requestRecorder) Do(request *http.Request) (*http.Response, error) {
+	*r.urls = append(*r.urls, request.URL.String())
+	return &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(strings.NewReader(`[]`)),
+	}, nil
+}
+
 type errorReader struct{}
 
 func (e errorReader) Read(p []byte) (int, error) {
@@ -0,0 +1,315 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIv4 is an implementation of API which works with Github GraphQL API (v4).
+// It fetches the Pull Requests in batches together with their review requests, comments and reviews,
+// so filling those details doesn't take any more requests unless there are too many of them.
+// The rest of the methods come from APIv3, including the diff size: GraphQL only counts the changed lines, not bytes
+type APIv4 struct {
+	APIv3
+	// BatchSize is how many Pull Requests to fetch per request, 50 if zero
+	BatchSize int
+}
+
+// graphQLURL returns the GraphQL endpoint next to BaseURL:
+// "https://api.github.com/graphql" or "https://github.example.com/api/graphql" for Github Enterprise
+func (a APIv4) graphQLURL() string {
+	baseURL := strings.TrimSuffix(a.url(""), "/")
+	if strings.HasSuffix(baseURL, "/api/v3") {
+		return strings.TrimSuffix(baseURL, "/v3") + "/graphql"
+	}
+	return baseURL + "/graphql"
+}
+
+// Query makes a GraphQL query and unmarshals the "data" of the response to the `target`
+func (a APIv4) Query(ctx context.Context, query string, variables map[string]interface{}, target interface{}) error {
+	body, _ := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+
+	req, _ := http.NewRequest("POST", a.graphQLURL(), bytes.NewReader(body))
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	if res.Body == nil {
+		return fmt.Errorf("Expected res.Body not to be nil. URL: %s", req.URL)
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	response := struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return err
+	}
+
+	if len(response.Errors) > 0 {
+		messages := []string{}
+		for _, e := range response.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("GraphQL query failed: %s", strings.Join(messages, "; "))
+	}
+
+	return json.Unmarshal(response.Data, target)
+}
+
+const pullRequestsQuery = `
+query($owner: String!, $name: String!, $states: [PullRequestState!], $field: IssueOrderField!, $first: Int!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(states: $states, orderBy: {field: $field, direction: DESC}, first: $first, after: $after) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        number body state createdAt updatedAt closedAt mergedAt
+        author { login }
+        assignees(first: 100) { nodes { login } }
+        reviewRequests(first: 100) {
+          totalCount
+          nodes { requestedReviewer { ... on User { login } } }
+        }
+        comments(first: 100) {
+          totalCount
+          nodes { author { login } body createdAt }
+        }
+        reviews(first: 100) {
+          totalCount
+          nodes {
+            databaseId state submittedAt
+            author { login }
+            comments(first: 50) {
+              totalCount
+              nodes { author { login } body createdAt }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// PullRequests fetches a list of Pull Requests given the options along with their details.
+// Like APIv3.PullRequests, it stops as soon as the rest of PRs are known to be outside the window
+func (a APIv4) PullRequests(ctx context.Context, opts PullRequestsOptions) ([]PullRequest, error) {
+	return opts.fetch(func(opts PullRequestsOptions) ([]PullRequest, error) {
+		return a.pullRequests(ctx, opts)
+	})
+}
+
+func (a APIv4) pullRequests(ctx context.Context, opts PullRequestsOptions) ([]PullRequest, error) {
+	split := strings.SplitN(a.RepoName, "/", 2)
+	if len(split) != 2 {
+		return nil, fmt.Errorf("Expected the repo name as \"owner/name\", got %q", a.RepoName)
+	}
+
+	batchSize := a.BatchSize
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	variables := map[string]interface{}{
+		"owner":  split[0],
+		"name":   split[1],
+		"states": graphQLStates(opts.State),
+		"field":  "CREATED_AT",
+		"first":  batchSize,
+	}
+	if opts.byUpdate() {
+		variables["field"] = "UPDATED_AT"
+	}
+
+	prs := []PullRequest{}
+	lastPage := opts.lastPage()
+	for {
+		response := struct {
+			Repository struct {
+				PullRequests struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []graphQLPullRequest `json:"nodes"`
+				} `json:"pullRequests"`
+			} `json:"repository"`
+		}{}
+		if err := a.Query(ctx, pullRequestsQuery, variables, &response); err != nil {
+			return nil, err
+		}
+
+		connection := response.Repository.PullRequests
+		page := []PullRequest{}
+		for _, node := range connection.Nodes {
+			page = append(page, node.pullRequest())
+		}
+		prs = append(prs, page...)
+
+		if !connection.PageInfo.HasNextPage || lastPage(page) {
+			break
+		}
+		variables["after"] = connection.PageInfo.EndCursor
+	}
+
+	return opts.Apply(prs), nil
+}
+
+// graphQLStates translates a state of the REST API to the GraphQL ones, nil means all
+func graphQLStates(state string) []string {
+	switch state {
+	case StateOpen:
+		return []string{"OPEN"}
+	case StateClosed:
+		return []string{"CLOSED", "MERGED"}
+	default:
+		return nil
+	}
+}
+
+type graphQLUser struct {
+	Login string `json:"login"`
+}
+
+// user converts the author of something, who is null in GraphQL if the account is deleted
+func (u *graphQLUser) user() User {
+	if u == nil {
+		// the REST API returns such a placeholder
+		return User{Login: "ghost"}
+	}
+	return User{Login: u.Login}
+}
+
+type graphQLComments struct {
+	TotalCount int `json:"totalCount"`
+	Nodes      []struct {
+		Author    *graphQLUser `json:"author"`
+		Body      string       `json:"body"`
+		CreatedAt time.Time    `json:"createdAt"`
+	} `json:"nodes"`
+}
+
+func (c graphQLComments) complete() bool {
+	return len(c.Nodes) >= c.TotalCount
+}
+
+func (c graphQLComments) comments() []Comment {
+	comments := []Comment{}
+	for _, n := range c.Nodes {
+		comments = append(comments, Comment{
+			User:      n.Author.user(),
+			Body:      n.Body,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+	return comments
+}
+
+type graphQLPullRequest struct {
+	Number    int          `json:"number"`
+	Body      string       `json:"body"`
+	State     string       `json:"state"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+	ClosedAt  time.Time    `json:"closedAt"`
+	MergedAt  time.Time    `json:"mergedAt"`
+	Author    *graphQLUser `json:"author"`
+	Assignees struct {
+		Nodes []graphQLUser `json:"nodes"`
+	} `json:"assignees"`
+	ReviewRequests struct {
+		TotalCount int `json:"totalCount"`
+		Nodes      []struct {
+			RequestedReviewer graphQLUser `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"reviewRequests"`
+	Comments graphQLComments `json:"comments"`
+	Reviews  struct {
+		TotalCount int `json:"totalCount"`
+		Nodes      []struct {
+			DatabaseID  int64           `json:"databaseId"`
+			State       ReviewState     `json:"state"`
+			SubmittedAt time.Time       `json:"submittedAt"`
+			Author      *graphQLUser    `json:"author"`
+			Comments    graphQLComments `json:"comments"`
+		} `json:"nodes"`
+	} `json:"reviews"`
+}
+
+// pullRequest converts the GraphQL representation to the one of the REST API.
+// A detail is only filled if GraphQL returned all of it, otherwise it's left for FillDetails
+func (n graphQLPullRequest) pullRequest() PullRequest {
+	p := PullRequest{
+		Number:    n.Number,
+		Body:      n.Body,
+		State:     StateClosed,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+		ClosedAt:  n.ClosedAt,
+		MergedAt:  n.MergedAt,
+		User:      n.Author.user(),
+		Assignees: []User{},
+	}
+	if n.State == "OPEN" {
+		p.State = StateOpen
+	}
+
+	for _, u := range n.Assignees.Nodes {
+		p.Assignees = append(p.Assignees, User{Login: u.Login})
+	}
+
+	if len(n.ReviewRequests.Nodes) >= n.ReviewRequests.TotalCount {
+		users := []User{}
+		for _, r := range n.ReviewRequests.Nodes {
+			// teams have no login and aren't listed by the REST API either
+			if r.RequestedReviewer.Login != "" {
+				users = append(users, User{Login: r.RequestedReviewer.Login})
+			}
+		}
+		p.ReviewRequests = &users
+	}
+
+	if len(n.Reviews.Nodes) >= n.Reviews.TotalCount {
+		reviews := []Review{}
+		// the review comments (the ones on the diff) are listed by the reviews they belong to
+		comments := n.Comments.comments()
+		commentsComplete := n.Comments.complete()
+
+		for _, r := range n.Reviews.Nodes {
+			reviews = append(reviews, Review{
+				ID:          r.DatabaseID,
+				User:        r.Author.user(),
+				State:       r.State,
+				SubmittedAt: r.SubmittedAt,
+			})
+			comments = append(comments, r.Comments.comments()...)
+			commentsComplete = commentsComplete && r.Comments.complete()
+		}
+
+		p.Reviews = &reviews
+		if commentsComplete {
+			p.Comments = &comments
+		}
+	}
+
+	return p
+}
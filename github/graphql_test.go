@@ -0,0 +1,172 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIv4(t *testing.T) {
+	// graphQLServer serves two pages of PRs on /api/graphql and records the variables of every query
+	graphQLServer := func(variables *[]map[string]interface{}) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "POST" || r.URL.Path != "/api/graphql" {
+				http.Error(w, "Not found", http.StatusNotFound)
+				return
+			}
+
+			query := struct {
+				Variables map[string]interface{} `json:"variables"`
+			}{}
+			if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			*variables = append(*variables, query.Variables)
+
+			if query.Variables["after"] == nil {
+				fmt.Fprint(w, `{"data": {"repository": {"pullRequests": {
+					"pageInfo": {"hasNextPage": true, "endCursor": "cursor1"},
+					"nodes": [{
+						"number": 2,
+						"state": "MERGED",
+						"createdAt": "2018-04-01T00:00:00Z",
+						"mergedAt": "2018-04-02T00:00:00Z",
+						"author": {"login": "alice"},
+						"assignees": {"nodes": [{"login": "bob"}]},
+						"reviewRequests": {"totalCount": 2, "nodes": [
+							{"requestedReviewer": {"login": "carol"}},
+							{"requestedReviewer": {}}
+						]},
+						"comments": {"totalCount": 1, "nodes": [{"author": null, "body": "Nice"}]},
+						"reviews": {"totalCount": 1, "nodes": [{
+							"databaseId": 100,
+							"state": "APPROVED",
+							"author": {"login": "bob"},
+							"comments": {"totalCount": 1, "nodes": [{"author": {"login": "bob"}, "body": "Typo"}]}
+						}]}
+					}]
+				}}}}`)
+				return
+			}
+
+			fmt.Fprint(w, `{"data": {"repository": {"pullRequests": {
+				"pageInfo": {"hasNextPage": false},
+				"nodes": [{
+					"number": 1,
+					"state": "CLOSED",
+					"createdAt": "2018-03-01T00:00:00Z",
+					"closedAt": "2018-03-02T00:00:00Z",
+					"author": {"login": "bob"},
+					"reviewRequests": {"totalCount": 0, "nodes": []},
+					"comments": {"totalCount": 150, "nodes": []},
+					"reviews": {"totalCount": 200, "nodes": []}
+				}]
+			}}}}`)
+		}))
+	}
+
+	t.Run("Fetches all the pages of PRs along with their details", func(t *testing.T) {
+		variables := []map[string]interface{}{}
+		server := graphQLServer(&variables)
+		defer server.Close()
+
+		a := APIv4{APIv3: APIv3{
+			HTTPClient: http.DefaultClient,
+			RepoName:   "someuser/somerepo",
+			BaseURL:    server.URL + "/api/v3",
+		}}
+
+		prs, err := a.PullRequests(context.Background(), PullRequestsOptions{State: StateClosed})
+		require.Nil(t, err)
+		require.Len(t, prs, 2)
+
+		require.Len(t, variables, 2)
+		require.Equal(t, "someuser", variables[0]["owner"])
+		require.Equal(t, "somerepo", variables[0]["name"])
+		require.Equal(t, []interface{}{"CLOSED", "MERGED"}, variables[0]["states"])
+		require.Equal(t, "CREATED_AT", variables[0]["field"])
+		require.Equal(t, float64(50), variables[0]["first"])
+		require.Equal(t, "cursor1", variables[1]["after"])
+
+		merged := prs[0]
+		require.Equal(t, 2, merged.Number)
+		require.True(t, merged.IsMerged())
+		require.Equal(t, "alice", merged.User.Login)
+		require.Equal(t, []User{{Login: "bob"}}, merged.Assignees)
+		require.Nil(t, merged.DiffSize)
+		require.Equal(t, []User{{Login: "carol"}}, *merged.ReviewRequests)
+		require.Equal(t, []Review{{ID: 100, User: User{Login: "bob"}, State: "APPROVED"}}, *merged.Reviews)
+		require.Equal(t, []Comment{
+			{User: User{Login: "ghost"}, Body: "Nice"},
+			{User: User{Login: "bob"}, Body: "Typo"},
+		}, *merged.Comments)
+
+		closed := prs[1]
+		require.Equal(t, 1, closed.Number)
+		require.Equal(t, StateClosed, closed.State)
+		require.False(t, closed.IsMerged())
+		require.Equal(t, time.Date(2018, 3, 2, 0, 0, 0, 0, time.UTC), closed.ClosedAt)
+		require.Equal(t, []User{}, *closed.ReviewRequests)
+		// too many to fetch with the list, left for FillDetails
+		require.Nil(t, closed.Comments)
+		require.Nil(t, closed.Reviews)
+	})
+
+	t.Run("Stops once the limit is reached", func(t *testing.T) {
+		variables := []map[string]interface{}{}
+		server := graphQLServer(&variables)
+		defer server.Close()
+
+		a := APIv4{APIv3: APIv3{
+			HTTPClient: http.DefaultClient,
+			RepoName:   "someuser/somerepo",
+			BaseURL:    server.URL + "/api/v3",
+		}, BatchSize: 1}
+
+		prs, err := a.PullRequests(context.Background(), PullRequestsOptions{State: StateAll, Limit: 1})
+		require.Nil(t, err)
+		require.Len(t, prs, 1)
+		require.Len(t, variables, 1)
+		require.Nil(t, variables[0]["states"])
+		require.Equal(t, float64(1), variables[0]["first"])
+	})
+
+	t.Run("Fails on GraphQL errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"data": null, "errors": [{"message": "Something went wrong"}, {"message": "Badly"}]}`)
+		}))
+		defer server.Close()
+
+		a := APIv4{APIv3: APIv3{
+			HTTPClient: http.DefaultClient,
+			RepoName:   "someuser/somerepo",
+			BaseURL:    server.URL,
+		}}
+
+		_, err := a.PullRequests(context.Background(), PullRequestsOptions{State: StateClosed})
+		require.EqualError(t, err, "GraphQL query failed: Something went wrong; Badly")
+	})
+
+	t.Run("Fails on a bad repo name", func(t *testing.T) {
+		a := APIv4{APIv3: APIv3{RepoName: "somerepo"}}
+
+		_, err := a.PullRequests(context.Background(), PullRequestsOptions{State: StateClosed})
+		require.EqualError(t, err, `Expected the repo name as "owner/name", got "somerepo"`)
+	})
+}
+
+func TestGraphQLURL(t *testing.T) {
+	require.Equal(t, "https://api.github.com/graphql", APIv4{}.graphQLURL())
+	require.Equal(
+		t,
+		"https://github.example.com/api/graphql",
+		APIv4{APIv3: APIv3{BaseURL: "https://github.example.com/api/v3/"}}.graphQLURL(),
+	)
+}
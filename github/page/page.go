@@ -2,6 +2,7 @@
 package page
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -16,55 +17,73 @@ import (
 
 // All fetches multiple pages given only a request for the first one and unmarshals them into `target`.
 // JSON of each response must be an array and `target` must be a pointer to a slice of the according type.
+// All the requests are made within `ctx`
 func All(
+	ctx context.Context,
 	httpClient client.HTTPClient,
 	firstPageRequest http.Request,
 	target interface{},
 	pageLimit int,
 ) error {
-	first, err := httpClient.Do(&firstPageRequest)
-	if err != nil {
-		return err
-	}
+	return AllUntil(ctx, httpClient, firstPageRequest, target, pageLimit, nil)
+}
 
-	rest, err := getRest(httpClient, *first, pageLimit-1)
+// AllUntil works like All, but also stops fetching once `done` returns true.
+// `done` is called after each page with a pointer to a slice containing only the items of that page
+func AllUntil(
+	ctx context.Context,
+	httpClient client.HTTPClient,
+	firstPageRequest http.Request,
+	target interface{},
+	pageLimit int,
+	done func(page interface{}) bool,
+) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if _, ok := e.(runtime.Error); ok {
+				panic(e)
+			}
+			err = e.(error)
+		}
+	}()
+
+	targetRefl := reflect.ValueOf(target).Elem()
+
+	cur, err := httpClient.Do(firstPageRequest.WithContext(ctx))
 	if err != nil {
 		return err
 	}
 
-	all := append([]http.Response{*first}, rest...)
-
-	return unmarshalResponses(all, target)
-}
-
-func getRest(
-	httpClient client.HTTPClient,
-	firstPageResponse http.Response,
-	limit int,
-) ([]http.Response, error) {
-	responses := []http.Response{}
+	for i := 0; ; i++ {
+		page, err := createSliceOfSameType(target)
+		if err != nil {
+			return err
+		}
 
-	cur := firstPageResponse
+		if err := unmarshalResponse(*cur, page); err != nil {
+			return err
+		}
+		targetRefl = reflect.AppendSlice(targetRefl, reflect.ValueOf(page).Elem())
 
-	for i := 0; ; {
-		if i+1 > limit {
-			return responses, nil
+		if i+1 > pageLimit-1 || (done != nil && done(page)) {
+			break
 		}
-		next, err := nextPage(httpClient, cur)
+
+		next, err := nextPage(ctx, httpClient, *cur)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if next == nil {
-			return responses, nil
+			break
 		}
-
-		responses = append(responses, *next)
-		cur = *next
-		i++
+		cur = next
 	}
+
+	reflect.ValueOf(target).Elem().Set(targetRefl)
+	return nil
 }
 
-func nextPage(httpClient client.HTTPClient, prevPageResponse http.Response) (*http.Response, error) {
+func nextPage(ctx context.Context, httpClient client.HTTPClient, prevPageResponse http.Response) (*http.Response, error) {
 	link := prevPageResponse.Header.Get("Link")
 	if link == "" {
 		return nil, nil
@@ -76,7 +95,7 @@ func nextPage(httpClient client.HTTPClient, prevPageResponse http.Response) (*ht
 	}
 
 	req, _ := http.NewRequest("GET", nextURL, nil)
-	return httpClient.Do(req)
+	return httpClient.Do(req.WithContext(ctx))
 }
 
 func extractLinkURL(header string, rel string) string {
@@ -92,34 +111,6 @@ func extractLinkURL(header string, rel string) string {
 	return ""
 }
 
-func unmarshalResponses(responses []http.Response, target interface{}) (err error) {
-	defer func() {
-		if e := recover(); e != nil {
-			if _, ok := e.(runtime.Error); ok {
-				panic(e)
-			}
-			err = e.(error)
-		}
-	}()
-
-	targetRefl := reflect.ValueOf(target).Elem()
-	tmp, err := createSliceOfSameType(target)
-	if err != nil {
-		return err
-	}
-
-	for _, res := range responses {
-		if err := unmarshalResponse(res, tmp); err != nil {
-			return err
-		}
-
-		targetRefl = reflect.AppendSlice(targetRefl, reflect.ValueOf(tmp).Elem())
-	}
-
-	reflect.ValueOf(target).Elem().Set(targetRefl)
-	return nil
-}
-
 func unmarshalResponse(res http.Response, target interface{}) error {
 	if res.Body == nil {
 		url := "<unknown>"
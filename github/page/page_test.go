@@ -1,6 +1,7 @@
 package page_test
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -39,7 +40,7 @@ func TestAll(t *testing.T) {
 		}
 
 		actual := &[]SomeStruct{}
-		err := All(httpClientMock{response}, http.Request{}, actual, 99)
+		err := All(context.Background(), httpClientMock{response}, http.Request{}, actual, 99)
 
 		expected := &[]SomeStruct{
 			{"val0"},
@@ -79,7 +80,7 @@ func TestAll(t *testing.T) {
 		}
 
 		actual := &[]SomeStruct{}
-		err := All(httpClientMock{response}, http.Request{}, actual, 2)
+		err := All(context.Background(), httpClientMock{response}, http.Request{}, actual, 2)
 
 		expected := &[]SomeStruct{
 			{"val0"},
@@ -94,7 +95,7 @@ func TestAll(t *testing.T) {
 	t.Run("Fails when couldn't fetch the first page", func(t *testing.T) {
 		result := &[]SomeStruct{}
 
-		err := All(httpClientMock{func() (*http.Response, error) {
+		err := All(context.Background(), httpClientMock{func() (*http.Response, error) {
 			return nil, fmt.Errorf("Some weird network error")
 		}}, *dummyRequest(), result, 99)
 
@@ -106,7 +107,7 @@ func TestAll(t *testing.T) {
 		result := &[]SomeStruct{}
 		req := dummyRequest()
 
-		err := All(httpClientMock{func() (*http.Response, error) {
+		err := All(context.Background(), httpClientMock{func() (*http.Response, error) {
 			return &http.Response{
 				Request: req,
 			}, nil
@@ -120,7 +121,7 @@ func TestAll(t *testing.T) {
 		result := &[]SomeStruct{}
 		req := dummyRequest()
 
-		err := All(httpClientMock{func() (*http.Response, error) {
+		err := All(context.Background(), httpClientMock{func() (*http.Response, error) {
 			return &http.Response{
 				Request: req,
 				Body:    ioutil.NopCloser(errorReader{}),
@@ -153,7 +154,7 @@ func TestAll(t *testing.T) {
 		}
 
 		result := &[]SomeStruct{}
-		err := All(httpClientMock{response}, *dummyRequest(), result, 99)
+		err := All(context.Background(), httpClientMock{response}, *dummyRequest(), result, 99)
 
 		require.Equal(t, []SomeStruct{}, *result)
 		require.Contains(t, err.Error(), "Some weird network error")
@@ -161,7 +162,7 @@ func TestAll(t *testing.T) {
 
 	t.Run("Stops fetching when there is no Link header in the response", func(t *testing.T) {
 		result := &[]SomeStruct{}
-		err := All(httpClientMock{func() (*http.Response, error) {
+		err := All(context.Background(), httpClientMock{func() (*http.Response, error) {
 			return &http.Response{
 				Body: ioutil.NopCloser(strings.NewReader(`[{"keyX": "val1"}]`)),
 			}, nil
@@ -173,7 +174,7 @@ func TestAll(t *testing.T) {
 
 	t.Run("Stops fetching when couldn't parse the Link header", func(t *testing.T) {
 		result := &[]SomeStruct{}
-		err := All(httpClientMock{func() (*http.Response, error) {
+		err := All(context.Background(), httpClientMock{func() (*http.Response, error) {
 			return &http.Response{
 				Header: http.Header{
 					"Link": []string{"Total rubbish"},
@@ -188,14 +189,14 @@ func TestAll(t *testing.T) {
 
 	t.Run("Fails when target is not a pointer", func(t *testing.T) {
 		result := []SomeStruct{}
-		err := All(httpClientMock{successfulResponse}, *dummyRequest(), result, 99)
+		err := All(context.Background(), httpClientMock{successfulResponse}, *dummyRequest(), result, 99)
 
 		require.NotNil(t, err)
 	})
 
 	t.Run("Fails when target is not a pointer to a slice", func(t *testing.T) {
 		result := &SomeStruct{}
-		err := All(httpClientMock{successfulResponse}, *dummyRequest(), result, 99)
+		err := All(context.Background(), httpClientMock{successfulResponse}, *dummyRequest(), result, 99)
 
 		require.NotNil(t, err)
 	})
@@ -224,13 +225,42 @@ func TestAll(t *testing.T) {
 		}
 
 		result := &[]SomeStruct{}
-		err := All(httpClientMock{response}, *dummyRequest(), result, 99)
+		err := All(context.Background(), httpClientMock{response}, *dummyRequest(), result, 99)
 
 		require.Equal(t, []SomeStruct{}, *result)
 		require.Contains(t, err.Error(), "cannot unmarshal string into Go value of type []page_test.SomeStruct")
 	})
 }
 
+func TestAllUntil(t *testing.T) {
+	t.Run("Stops fetching once done returns true", func(t *testing.T) {
+		link := `<https://api.github.com/user/repos?page=3&per_page=100>; rel="next"`
+
+		timesCalled := 0
+		response := func() (*http.Response, error) {
+			defer func() { timesCalled++ }()
+			json := fmt.Sprintf("[{\"keyX\": \"val%d\"}]", timesCalled)
+			return &http.Response{
+				Header: http.Header{"Link": []string{link}},
+				Body:   ioutil.NopCloser(strings.NewReader(json)),
+			}, nil
+		}
+
+		pages := []SomeStruct{}
+		actual := &[]SomeStruct{}
+		err := AllUntil(context.Background(), httpClientMock{response}, http.Request{}, actual, 99, func(page interface{}) bool {
+			items := *page.(*[]SomeStruct)
+			pages = append(pages, items...)
+			return items[0].KeyX == "val1"
+		})
+
+		require.Nil(t, err)
+		require.Equal(t, 2, timesCalled)
+		require.Equal(t, []SomeStruct{{"val0"}, {"val1"}}, pages)
+		require.Equal(t, &[]SomeStruct{{"val0"}, {"val1"}}, actual)
+	})
+}
+
 type httpClientMock struct {
 	response func() (*http.Response, error)
 }
@@ -1,9 +1,11 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/kirillrogovoy/pullkee/github/page"
@@ -15,6 +17,8 @@ type PullRequest struct {
 	Number         int       `json:"number"`
 	Body           string    `json:"body"`
 	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	ClosedAt       time.Time `json:"closed_at,omitempty"`
 	MergedAt       time.Time `json:"merged_at,omitempty"`
 	User           User      `json:"user"`
 	State          string    `json:"state"`
@@ -23,64 +27,258 @@ type PullRequest struct {
 	DiffSize       *int
 	ReviewRequests *[]User
 	Comments       *[]Comment
+	Reviews        *[]Review
+}
+
+// Possible states of a Pull Request to filter by
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+	StateAll    = "all"
+)
+
+// PullRequestsOptions narrows down the list of Pull Requests to fetch
+type PullRequestsOptions struct {
+	State string    // one of StateOpen, StateClosed or StateAll
+	Limit int       // only fetch N last Pull Requests, 0 means no limit
+	Since time.Time // only fetch PRs which were merged, closed or still open after that time
+	Until time.Time // only fetch PRs which were created before that time
+	// UpdatedSince only fetches PRs which were updated after that time, e.g. to catch up with the changes
+	UpdatedSince time.Time
+}
+
+// InWindow tells if the Pull Request was alive at some point between Since and Until
+func (o PullRequestsOptions) InWindow(p PullRequest) bool {
+	if !o.Until.IsZero() && p.CreatedAt.After(o.Until) {
+		return false
+	}
+
+	if p.UpdatedAt.Before(o.UpdatedSince) {
+		return false
+	}
+
+	if !o.Since.IsZero() && !p.IsOpen() {
+		end := p.MergedAt
+		if end.IsZero() {
+			end = p.ClosedAt
+		}
+		if end.Before(o.Since) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsOpen tells if PullRequest is still waiting to be merged or closed
+func (p PullRequest) IsOpen() bool {
+	return p.State == StateOpen
 }
 
 // IsMerged tells if PullRequest was really merged, not just closed
 func (p PullRequest) IsMerged() bool {
-	return p.State == "closed" && !p.MergedAt.IsZero()
+	return p.State == StateClosed && !p.MergedAt.IsZero()
 }
 
-// FillDetails makes additional requests to fill details about the Pull Request (such as diff size)
-func (p *PullRequest) FillDetails(a API) error {
-	if p.DiffSize == nil {
-		size, err := a.DiffSize(p.Number)
-		if err != nil {
-			return errors.Wrap(err, "diff size")
+// Detail is a piece of information about a Pull Request which takes additional requests to fetch
+type Detail string
+
+// Possible details of a Pull Request
+const (
+	DetailDiffSize       Detail = "diff size"
+	DetailReviewRequests Detail = "review requests"
+	DetailComments       Detail = "comments"
+	DetailReviews        Detail = "reviews"
+)
+
+// AllDetails is the list of all the details of a Pull Request
+var AllDetails = []Detail{DetailDiffSize, DetailReviewRequests, DetailComments, DetailReviews}
+
+// FillDetails makes additional requests to fill the given `details` of the Pull Request (such as diff size).
+// The details which are already filled aren't fetched again
+func (p *PullRequest) FillDetails(ctx context.Context, a API, details []Detail) error {
+	for _, d := range details {
+		if err := p.fillDetail(ctx, a, d); err != nil {
+			return errors.Wrap(err, string(d))
 		}
-		p.DiffSize = &size
 	}
 
-	if p.ReviewRequests == nil {
-		users, err := a.ReviewRequests(p.Number)
-		if err != nil {
-			return errors.Wrap(err, "review requests")
+	return nil
+}
+
+func (p *PullRequest) fillDetail(ctx context.Context, a API, d Detail) error {
+	switch d {
+	case DetailDiffSize:
+		if p.DiffSize == nil {
+			size, err := a.DiffSize(ctx, p.Number)
+			if err != nil {
+				return err
+			}
+			p.DiffSize = &size
+		}
+	case DetailReviewRequests:
+		if p.ReviewRequests == nil {
+			users, err := a.ReviewRequests(ctx, p.Number)
+			if err != nil {
+				return err
+			}
+			p.ReviewRequests = &users
+		}
+	case DetailComments:
+		if p.Comments == nil {
+			comments, err := a.Comments(ctx, p.Number)
+			if err != nil {
+				return err
+			}
+			p.Comments = &comments
+		}
+	case DetailReviews:
+		if p.Reviews == nil {
+			reviews, err := a.Reviews(ctx, p.Number)
+			if err != nil {
+				return err
+			}
+			p.Reviews = &reviews
 		}
-		p.ReviewRequests = &users
+	default:
+		return fmt.Errorf("Unknown detail %q", d)
 	}
 
-	if p.Comments == nil {
-		comments, err := a.Comments(p.Number)
-		if err != nil {
-			return errors.Wrap(err, "comments")
+	return nil
+}
+
+// HasDetails tells if all the given `details` are already filled
+func (p PullRequest) HasDetails(details []Detail) bool {
+	for _, d := range details {
+		switch {
+		case d == DetailDiffSize && p.DiffSize == nil,
+			d == DetailReviewRequests && p.ReviewRequests == nil,
+			d == DetailComments && p.Comments == nil,
+			d == DetailReviews && p.Reviews == nil:
+			return false
 		}
-		p.Comments = &comments
 	}
+	return true
+}
 
-	return nil
+// PullRequests fetches a list of Pull Requests given the options.
+// If there is a time window, the list is sorted by the update time so that
+// the pagination stops as soon as the rest of PRs are known to be outside the window
+func (a APIv3) PullRequests(ctx context.Context, opts PullRequestsOptions) ([]PullRequest, error) {
+	return opts.fetch(func(opts PullRequestsOptions) ([]PullRequest, error) {
+		return a.pullRequests(ctx, opts)
+	})
 }
 
-// ClosedPullRequests fetches a list of closed Pull Requests with a `limit`
-func (a APIv3) ClosedPullRequests(limit int) ([]PullRequest, error) {
+func (a APIv3) pullRequests(ctx context.Context, opts PullRequestsOptions) ([]PullRequest, error) {
+	limit := opts.Limit
 	perPage := 100
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/pulls?state=closed&per_page=%d&page=1",
+	windowed := !opts.Since.IsZero() || !opts.Until.IsZero() || !opts.UpdatedSince.IsZero()
+
+	sort := ""
+	if opts.byUpdate() {
+		sort = "&sort=updated&direction=desc"
+	}
+
+	url := a.url(
+		"/repos/%s/pulls?state=%s%s&per_page=%d&page=1",
 		a.RepoName,
+		opts.State,
+		sort,
 		perPage,
 	)
 	req, _ := http.NewRequest("GET", url, nil)
 
 	prs := []PullRequest{}
 	pageLimit := int(math.Ceil(float64(limit) / float64(perPage)))
-	if limit <= 0 {
+	if limit <= 0 || windowed {
 		pageLimit = int(math.Inf(1))
 	}
-	if err := page.All(a.HTTPClient, *req, &prs, pageLimit); err != nil {
+
+	lastPage := opts.lastPage()
+	done := func(page interface{}) bool {
+		return lastPage(*page.(*[]PullRequest))
+	}
+	if err := page.AllUntil(ctx, a.HTTPClient, *req, &prs, pageLimit, done); err != nil {
 		return nil, err
 	}
 
-	len := len(prs)
-	if limit > len || limit == 0 {
-		limit = len
+	return opts.Apply(prs), nil
+}
+
+// fetch lists the Pull Requests using `list`. With Since, the open ones are listed separately without it:
+// they are all in the window, even the ones which haven't been updated for long, so the listing by
+// the update time can't stop early for them
+func (o PullRequestsOptions) fetch(list func(PullRequestsOptions) ([]PullRequest, error)) ([]PullRequest, error) {
+	if o.Since.IsZero() || o.State == StateClosed {
+		return list(o)
+	}
+
+	open := o
+	open.State = StateOpen
+	open.Since = time.Time{}
+	prs, err := list(open)
+	if err != nil || o.State == StateOpen {
+		return prs, err
+	}
+
+	closed := o
+	closed.State = StateClosed
+	closedPRs, err := list(closed)
+	if err != nil {
+		return nil, err
+	}
+
+	prs = append(prs, closedPRs...)
+	sort.SliceStable(prs, func(i, j int) bool {
+		return prs[i].UpdatedAt.After(prs[j].UpdatedAt)
+	})
+	return o.Apply(prs), nil
+}
+
+// byUpdate tells if the Pull Requests should be listed by the update time instead of the creation time
+// so that the ones outside the window come last
+func (o PullRequestsOptions) byUpdate() bool {
+	return !o.Since.IsZero() || !o.UpdatedSince.IsZero()
+}
+
+// lastPage returns a function which tells if there is no need to fetch more pages after the given one.
+// It expects the pages to be sorted by the update time if byUpdate(), by the creation time otherwise
+func (o PullRequestsOptions) lastPage() func(page []PullRequest) bool {
+	// updated_at is never earlier than merged_at or closed_at
+	updatedSince := o.Since
+	if o.UpdatedSince.After(updatedSince) {
+		updatedSince = o.UpdatedSince
+	}
+
+	matched := 0
+	return func(page []PullRequest) bool {
+		outdated := true
+		for _, p := range page {
+			if o.InWindow(p) {
+				matched++
+			}
+			if !p.UpdatedAt.Before(updatedSince) {
+				outdated = false
+			}
+		}
+		return (o.byUpdate() && outdated) || (o.Limit > 0 && matched >= o.Limit)
+	}
+}
+
+// Apply filters the Pull Requests by the window and cuts them by the limit.
+// They are expected to be in the order the API lists them
+func (o PullRequestsOptions) Apply(prs []PullRequest) []PullRequest {
+	filtered := []PullRequest{}
+	for _, p := range prs {
+		if o.InWindow(p) {
+			filtered = append(filtered, p)
+		}
+	}
+	prs = filtered
+
+	if o.Limit > 0 && o.Limit < len(prs) {
+		return prs[:o.Limit]
 	}
-	return prs[:limit], nil
+	return prs
 }
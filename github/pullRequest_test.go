@@ -1,6 +1,7 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -12,7 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestClosedPullRequests(t *testing.T) {
+func TestPullRequests(t *testing.T) {
 	t.Run("Works on good response", func(t *testing.T) {
 		pulls, err := successfulClosedPullRequests(0)
 		require.Nil(t, err)
@@ -53,10 +54,29 @@ func TestClosedPullRequests(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		pulls, err := a.ClosedPullRequests(0)
+		pulls, err := a.PullRequests(context.Background(), PullRequestsOptions{State: StateClosed})
 		require.EqualError(t, err, "Dogs have chewed the wires")
 		require.Nil(t, pulls)
 	})
+
+	t.Run("Requests Pull Requests in the given state", func(t *testing.T) {
+		urls := []string{}
+		a := APIv3{
+			HTTPClient: requestRecorder{&urls},
+			RepoName:   "someuser/somerepo",
+		}
+
+		_, err := a.PullRequests(context.Background(), PullRequestsOptions{State: StateOpen})
+		require.Nil(t, err)
+		require.Equal(t, []string{
+			"https://api.github.com/repos/someuser/somerepo/pulls?state=open&per_page=100&page=1",
+		}, urls)
+	})
+}
+
+func TestIsOpen(t *testing.T) {
+	require.True(t, PullRequest{State: "open"}.IsOpen())
+	require.False(t, PullRequest{State: "closed"}.IsOpen())
 }
 
 func TestIsMerged(t *testing.T) {
@@ -87,12 +107,13 @@ func TestFillDetails(t *testing.T) {
 
 		a := apiMock{}
 
-		err := pr.FillDetails(a)
+		err := pr.FillDetails(context.Background(), a, AllDetails)
 
 		require.Nil(t, err)
 		require.Equal(t, 100, *pr.DiffSize)
 		require.Equal(t, "Neat!", (*pr.Comments)[0].Body)
 		require.Equal(t, "User1", (*pr.ReviewRequests)[0].Login)
+		require.Equal(t, ReviewApproved, (*pr.Reviews)[0].State)
 	})
 
 	t.Run("Fails when couldn't fetch the diff size", func(t *testing.T) {
@@ -100,9 +121,9 @@ func TestFillDetails(t *testing.T) {
 			Number: 11,
 		}
 
-		err := pr.FillDetails(apiMock{
+		err := pr.FillDetails(context.Background(), apiMock{
 			diffSizeErr: fmt.Errorf("Weird error"),
-		})
+		}, AllDetails)
 		require.EqualError(t, err, "diff size: Weird error")
 	})
 
@@ -111,9 +132,9 @@ func TestFillDetails(t *testing.T) {
 			Number: 11,
 		}
 
-		err := pr.FillDetails(apiMock{
+		err := pr.FillDetails(context.Background(), apiMock{
 			commentsErr: fmt.Errorf("Weird error"),
-		})
+		}, AllDetails)
 		require.EqualError(t, err, "comments: Weird error")
 	})
 
@@ -122,11 +143,47 @@ func TestFillDetails(t *testing.T) {
 			Number: 11,
 		}
 
-		err := pr.FillDetails(apiMock{
+		err := pr.FillDetails(context.Background(), apiMock{
 			reviewRequestsErr: fmt.Errorf("Weird error"),
-		})
+		}, AllDetails)
 		require.EqualError(t, err, "review requests: Weird error")
 	})
+
+	t.Run("Fails when couldn't fetch the reviews", func(t *testing.T) {
+		pr := PullRequest{
+			Number: 11,
+		}
+
+		err := pr.FillDetails(context.Background(), apiMock{
+			reviewsErr: fmt.Errorf("Weird error"),
+		}, AllDetails)
+		require.EqualError(t, err, "reviews: Weird error")
+	})
+}
+
+func TestFillSomeDetails(t *testing.T) {
+	t.Run("Only fetches the given details", func(t *testing.T) {
+		pr := PullRequest{
+			Number: 11,
+		}
+
+		err := pr.FillDetails(context.Background(), apiMock{
+			diffSizeErr: fmt.Errorf("Should not be called"),
+		}, []Detail{DetailComments})
+
+		require.Nil(t, err)
+		require.Nil(t, pr.DiffSize)
+		require.Equal(t, "Neat!", (*pr.Comments)[0].Body)
+		require.True(t, pr.HasDetails([]Detail{DetailComments}))
+		require.False(t, pr.HasDetails(AllDetails))
+	})
+
+	t.Run("Fails on an unknown detail", func(t *testing.T) {
+		pr := PullRequest{}
+
+		err := pr.FillDetails(context.Background(), apiMock{}, []Detail{"mood"})
+		require.EqualError(t, err, `mood: Unknown detail "mood"`)
+	})
 }
 
 func successfulClosedPullRequests(limit int) ([]PullRequest, error) {
@@ -160,28 +217,29 @@ func successfulClosedPullRequests(limit int) ([]PullRequest, error) {
 		RepoName:   "someuser/somerepo",
 	}
 
-	return a.ClosedPullRequests(limit)
+	return a.PullRequests(context.Background(), PullRequestsOptions{State: StateClosed, Limit: limit})
 }
 
 type apiMock struct {
 	diffSizeErr       error
 	commentsErr       error
 	reviewRequestsErr error
+	reviewsErr        error
 }
 
-func (a apiMock) Get(url string, target interface{}) error {
+func (a apiMock) Get(ctx context.Context, url string, target interface{}) error {
 	panic("not implemented")
 }
 
-func (a apiMock) Repository() (*Repository, error) {
+func (a apiMock) Repository(ctx context.Context) (*Repository, error) {
 	panic("not implemented")
 }
 
-func (a apiMock) ClosedPullRequests(limit int) ([]PullRequest, error) {
+func (a apiMock) PullRequests(ctx context.Context, opts PullRequestsOptions) ([]PullRequest, error) {
 	panic("not implemented")
 }
 
-func (a apiMock) DiffSize(number int) (int, error) {
+func (a apiMock) DiffSize(ctx context.Context, number int) (int, error) {
 	if a.diffSizeErr != nil {
 		return 0, a.diffSizeErr
 	}
@@ -189,7 +247,7 @@ func (a apiMock) DiffSize(number int) (int, error) {
 	return 100, nil
 }
 
-func (a apiMock) Comments(number int) ([]Comment, error) {
+func (a apiMock) Comments(ctx context.Context, number int) ([]Comment, error) {
 	if a.commentsErr != nil {
 		return nil, a.commentsErr
 	}
@@ -197,7 +255,7 @@ func (a apiMock) Comments(number int) ([]Comment, error) {
 	return []Comment{{Body: "Neat!"}}, nil
 }
 
-func (a apiMock) ReviewRequests(number int) ([]User, error) {
+func (a apiMock) ReviewRequests(ctx context.Context, number int) ([]User, error) {
 	if a.reviewRequestsErr != nil {
 		log.Println("not nil")
 		return nil, a.reviewRequestsErr
@@ -205,3 +263,165 @@ func (a apiMock) ReviewRequests(number int) ([]User, error) {
 
 	return []User{{"User1"}}, nil
 }
+
+func TestInWindow(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2018, 4, d, 0, 0, 0, 0, time.UTC)
+	}
+	opts := PullRequestsOptions{Since: day(10), Until: day(20)}
+
+	require.True(t, opts.InWindow(PullRequest{State: "closed", CreatedAt: day(1), MergedAt: day(11)}))
+	require.True(t, opts.InWindow(PullRequest{State: "closed", CreatedAt: day(12), ClosedAt: day(25)}))
+	require.True(t, opts.InWindow(PullRequest{State: "open", CreatedAt: day(1)}))
+	require.False(t, opts.InWindow(PullRequest{State: "closed", CreatedAt: day(1), MergedAt: day(5)}))
+	require.False(t, opts.InWindow(PullRequest{State: "closed", CreatedAt: day(1), ClosedAt: day(5)}))
+	require.False(t, opts.InWindow(PullRequest{State: "open", CreatedAt: day(21)}))
+	require.True(t, PullRequestsOptions{}.InWindow(PullRequest{State: "closed", CreatedAt: day(1)}))
+}
+
+func TestPullRequestsWindow(t *testing.T) {
+	t.Run("Stops paginating once the PRs are older than the window", func(t *testing.T) {
+		link := `<https://api.github.com/repos/someuser/somerepo/pulls?page=2>; rel="next"`
+
+		urls := []string{}
+		timesCalled := 0
+		response := func(req *http.Request) (*http.Response, error) {
+			defer func() { timesCalled++ }()
+			urls = append(urls, req.URL.String())
+			switch timesCalled {
+			case 0:
+				json := `[
+					{"number": 3, "state": "closed", "created_at": "2018-04-15T00:00:00Z", "merged_at": "2018-04-16T00:00:00Z", "updated_at": "2018-04-16T00:00:00Z"},
+					{"number": 2, "state": "closed", "created_at": "2018-04-01T00:00:00Z", "merged_at": "2018-04-12T00:00:00Z", "updated_at": "2018-04-12T00:00:00Z"}
+				]`
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(strings.NewReader(json)),
+					Header:     http.Header{"Link": []string{link}},
+				}, nil
+			case 1:
+				json := `[
+					{"number": 1, "state": "closed", "created_at": "2018-04-01T00:00:00Z", "merged_at": "2018-04-02T00:00:00Z", "updated_at": "2018-04-02T00:00:00Z"}
+				]`
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(strings.NewReader(json)),
+					Header:     http.Header{"Link": []string{link}},
+				}, nil
+			default:
+				panic("Should not be called")
+			}
+		}
+
+		a := APIv3{
+			HTTPClient: httpClientFunc(response),
+			RepoName:   "someuser/somerepo",
+		}
+
+		pulls, err := a.PullRequests(context.Background(), PullRequestsOptions{
+			State: StateClosed,
+			Since: time.Date(2018, 4, 10, 0, 0, 0, 0, time.UTC),
+			Until: time.Date(2018, 4, 14, 0, 0, 0, 0, time.UTC),
+		})
+
+		require.Nil(t, err)
+		require.Len(t, pulls, 1)
+		require.Equal(t, 2, pulls[0].Number)
+		require.Equal(t, 2, timesCalled)
+		require.Equal(
+			t,
+			"https://api.github.com/repos/someuser/somerepo/pulls?state=closed&sort=updated&direction=desc&per_page=100&page=1",
+			urls[0],
+		)
+	})
+
+	t.Run("Fetches the open PRs which haven't been updated since the window started", func(t *testing.T) {
+		urls := []string{}
+		response := func(req *http.Request) (*http.Response, error) {
+			urls = append(urls, req.URL.String())
+			json := `[
+				{"number": 1, "state": "open", "created_at": "2018-01-01T00:00:00Z", "updated_at": "2018-01-02T00:00:00Z"}
+			]`
+			if req.URL.Query().Get("state") == StateClosed {
+				json = `[
+					{"number": 3, "state": "closed", "created_at": "2018-04-01T00:00:00Z", "merged_at": "2018-04-12T00:00:00Z", "updated_at": "2018-04-12T00:00:00Z"},
+					{"number": 2, "state": "closed", "created_at": "2018-03-01T00:00:00Z", "merged_at": "2018-03-02T00:00:00Z", "updated_at": "2018-03-02T00:00:00Z"}
+				]`
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(json)),
+				Header:     http.Header{},
+			}, nil
+		}
+
+		a := APIv3{
+			HTTPClient: httpClientFunc(response),
+			RepoName:   "someuser/somerepo",
+		}
+
+		pulls, err := a.PullRequests(context.Background(), PullRequestsOptions{
+			State: StateAll,
+			Since: time.Date(2018, 4, 10, 0, 0, 0, 0, time.UTC),
+		})
+
+		require.Nil(t, err)
+		require.Len(t, pulls, 2)
+		require.Equal(t, 3, pulls[0].Number)
+		require.Equal(t, 1, pulls[1].Number)
+		require.Equal(t, []string{
+			"https://api.github.com/repos/someuser/somerepo/pulls?state=open&per_page=100&page=1",
+			"https://api.github.com/repos/someuser/somerepo/pulls?state=closed&sort=updated&direction=desc&per_page=100&page=1",
+		}, urls)
+	})
+
+	t.Run("Only fetches the PRs updated since the given time", func(t *testing.T) {
+		urls := []string{}
+		response := func(req *http.Request) (*http.Response, error) {
+			urls = append(urls, req.URL.String())
+			json := `[
+				{"number": 3, "state": "open", "created_at": "2018-04-15T00:00:00Z", "updated_at": "2018-04-16T00:00:00Z"},
+				{"number": 1, "state": "closed", "created_at": "2018-03-01T00:00:00Z", "closed_at": "2018-03-02T00:00:00Z", "updated_at": "2018-04-12T00:00:00Z"}
+			]`
+			if len(urls) > 1 {
+				json = `[
+					{"number": 2, "state": "closed", "created_at": "2018-04-01T00:00:00Z", "merged_at": "2018-04-02T00:00:00Z", "updated_at": "2018-04-02T00:00:00Z"}
+				]`
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(json)),
+				Header:     http.Header{"Link": []string{`<https://api.github.com/repos/someuser/somerepo/pulls?page=2>; rel="next"`}},
+			}, nil
+		}
+
+		a := APIv3{
+			HTTPClient: httpClientFunc(response),
+			RepoName:   "someuser/somerepo",
+		}
+
+		pulls, err := a.PullRequests(context.Background(), PullRequestsOptions{
+			State:        StateAll,
+			UpdatedSince: time.Date(2018, 4, 10, 0, 0, 0, 0, time.UTC),
+		})
+
+		require.Nil(t, err)
+		require.Len(t, pulls, 2)
+		require.Equal(t, 3, pulls[0].Number)
+		require.Equal(t, 1, pulls[1].Number)
+		require.Len(t, urls, 2)
+		require.Equal(
+			t,
+			"https://api.github.com/repos/someuser/somerepo/pulls?state=all&sort=updated&direction=desc&per_page=100&page=1",
+			urls[0],
+		)
+	})
+}
+
+func (a apiMock) Reviews(ctx context.Context, number int) ([]Review, error) {
+	if a.reviewsErr != nil {
+		return nil, a.reviewsErr
+	}
+
+	return []Review{{User: User{"User1"}, State: ReviewApproved}}, nil
+}
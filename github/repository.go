@@ -1,6 +1,6 @@
 package github
 
-import "fmt"
+import "context"
 
 // Repository is a representation of a Github repository which is accessible via API
 type Repository struct {
@@ -8,10 +8,10 @@ type Repository struct {
 }
 
 // Repository fetches the remote repository data
-func (a APIv3) Repository() (*Repository, error) {
+func (a APIv3) Repository(ctx context.Context) (*Repository, error) {
 	repo := &Repository{}
 
-	if err := a.Get(fmt.Sprintf("https://api.github.com/repos/%s", a.RepoName), repo); err != nil {
+	if err := a.Get(ctx, a.url("/repos/%s", a.RepoName), repo); err != nil {
 		return nil, err
 	}
 
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -22,7 +23,7 @@ func TestRepository(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		repo, err := a.Repository()
+		repo, err := a.Repository(context.Background())
 		require.Nil(t, err)
 		require.Equal(t, "someuser/somerepo", repo.FullName)
 	})
@@ -35,7 +36,7 @@ func TestRepository(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		repo, err := a.Repository()
+		repo, err := a.Repository(context.Background())
 		require.EqualError(t, err, "Dogs have chewed the wires")
 		require.Nil(t, repo)
 	})
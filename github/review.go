@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/kirillrogovoy/pullkee/github/page"
+)
+
+// ReviewState is the outcome of a Pull Request review
+type ReviewState string
+
+// Possible states of a review
+const (
+	ReviewApproved         ReviewState = "APPROVED"
+	ReviewChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewCommented        ReviewState = "COMMENTED"
+	ReviewDismissed        ReviewState = "DISMISSED"
+	ReviewPending          ReviewState = "PENDING"
+)
+
+// Review is a representation of a Pull Request review
+type Review struct {
+	ID          int64       `json:"id"`
+	User        User        `json:"user"`
+	State       ReviewState `json:"state"`
+	SubmittedAt time.Time   `json:"submitted_at"`
+}
+
+// Reviews fetches all the reviews of a Pull Request given its `number`
+func (a APIv3) Reviews(ctx context.Context, number int) ([]Review, error) {
+	url := a.url(
+		"/repos/%s/pulls/%d/reviews?per_page=100",
+		a.RepoName,
+		number,
+	)
+	req, _ := http.NewRequest("GET", url, nil)
+
+	reviews := []Review{}
+	pageLimit := int(math.Inf(1))
+	if err := page.All(ctx, a.HTTPClient, *req, &reviews, pageLimit); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// Approvers returns the users whose latest review of the Pull Request is an approval.
+// Reviews are expected to be in the chronological order as the API returns them
+func (p PullRequest) Approvers() []User {
+	if p.Reviews == nil {
+		return nil
+	}
+
+	latest := map[string]ReviewState{}
+	order := []User{}
+	for _, r := range *p.Reviews {
+		// comments don't change the previous verdict of the reviewer
+		if r.State == ReviewCommented || r.State == ReviewPending {
+			continue
+		}
+		if _, ok := latest[r.User.Login]; !ok {
+			order = append(order, r.User)
+		}
+		latest[r.User.Login] = r.State
+	}
+
+	approvers := []User{}
+	for _, u := range order {
+		if latest[u.Login] == ReviewApproved {
+			approvers = append(approvers, u)
+		}
+	}
+	return approvers
+}
@@ -1,6 +1,6 @@
 package github
 
-import "fmt"
+import "context"
 
 // ReviewRequestsResponse is a representation of the /requested_reviewers API response
 type ReviewRequestsResponse struct {
@@ -8,10 +8,10 @@ type ReviewRequestsResponse struct {
 }
 
 // ReviewRequests fetches a list of users which were requested to do a review
-func (a APIv3) ReviewRequests(number int) ([]User, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/pulls/%d/requested_reviewers", a.RepoName, number)
+func (a APIv3) ReviewRequests(ctx context.Context, number int) ([]User, error) {
+	url := a.url("/repos/%s/pulls/%d/requested_reviewers", a.RepoName, number)
 	response := ReviewRequestsResponse{}
-	err := a.Get(url, &response)
+	err := a.Get(ctx, url, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -24,7 +25,7 @@ func TestReviewRequest(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		users, err := a.ReviewRequests(1)
+		users, err := a.ReviewRequests(context.Background(), 1)
 		require.Nil(t, err)
 		require.Equal(t, []User{{"User1"}}, users)
 	})
@@ -37,7 +38,7 @@ func TestReviewRequest(t *testing.T) {
 			RepoName: "someuser/somerepo",
 		}
 
-		_, err := a.ReviewRequests(1)
+		_, err := a.ReviewRequests(context.Background(), 1)
 		require.EqualError(t, err, "Some weird network error")
 	})
 }
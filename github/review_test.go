@@ -0,0 +1,69 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviews(t *testing.T) {
+	t.Run("Works on good response", func(t *testing.T) {
+		a := APIv3{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				json := `[{"id": 42, "user": {"login": "User1"}, "state": "APPROVED", "submitted_at": "2018-04-28T12:00:00Z"}]`
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(strings.NewReader(json)),
+				}, nil
+			}},
+			RepoName: "someuser/somerepo",
+		}
+
+		reviews, err := a.Reviews(context.Background(), 1)
+		require.Nil(t, err)
+		require.Equal(t, []Review{{
+			ID:          42,
+			User:        User{"User1"},
+			State:       ReviewApproved,
+			SubmittedAt: time.Date(2018, 4, 28, 12, 0, 0, 0, time.UTC),
+		}}, reviews)
+	})
+
+	t.Run("Fails when there is an error fetching the response", func(t *testing.T) {
+		a := APIv3{
+			HTTPClient: httpClientMock{func() (*http.Response, error) {
+				return nil, fmt.Errorf("Dogs have chewed the wires")
+			}},
+			RepoName: "someuser/somerepo",
+		}
+
+		reviews, err := a.Reviews(context.Background(), 1)
+		require.EqualError(t, err, "Dogs have chewed the wires")
+		require.Nil(t, reviews)
+	})
+}
+
+func TestApprovers(t *testing.T) {
+	t.Run("Only counts the latest verdict of each reviewer", func(t *testing.T) {
+		pr := PullRequest{Reviews: &[]Review{
+			{User: User{"User1"}, State: ReviewChangesRequested},
+			{User: User{"User2"}, State: ReviewApproved},
+			{User: User{"User1"}, State: ReviewApproved},
+			{User: User{"User3"}, State: ReviewApproved},
+			{User: User{"User3"}, State: ReviewDismissed},
+			{User: User{"User2"}, State: ReviewCommented},
+		}}
+
+		require.Equal(t, []User{{"User1"}, {"User2"}}, pr.Approvers())
+	})
+
+	t.Run("Returns nil if the reviews weren't fetched", func(t *testing.T) {
+		require.Nil(t, PullRequest{}.Approvers())
+	})
+}
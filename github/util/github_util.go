@@ -2,8 +2,12 @@
 package util
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/kirillrogovoy/pullkee/cache"
 	"github.com/kirillrogovoy/pullkee/github"
@@ -11,50 +15,259 @@ import (
 )
 
 // Pulls fetches the list of pull requests directly from the API
-func Pulls(a github.API, limit int) ([]github.PullRequest, error) {
-	return a.ClosedPullRequests(limit)
+func Pulls(ctx context.Context, a github.API, opts github.PullRequestsOptions) ([]github.PullRequest, error) {
+	return a.PullRequests(ctx, opts)
 }
 
-// FillDetails calls .FillDetails for each PR in prs in parallel.
-// It returns a channel which will never be closed, so the caller
-// should expect len(prs) values from it
+// pullsCacheKey is where SyncPulls keeps the list of all the pull requests of the repo
+const pullsCacheKey = "pulls"
+
+// pullList is the list of the pull requests of the repo as it's kept in the cache
+type pullList struct {
+	// Synced is the high-water mark: the latest update time among the pull requests
+	Synced time.Time
+	// From is the time since which every updated pull request is known, zero means the whole history.
+	// The open pull requests are all known regardless
+	From  time.Time
+	Pulls []github.PullRequest
+}
+
+// covers tells if the list has every pull request updated since `since`
+func (l pullList) covers(since time.Time) bool {
+	return !l.Synced.IsZero() && !since.Before(l.From)
+}
+
+// SyncPulls is like Pulls but it keeps the list of the pull requests in the cache.
+// The first call fetches the pull requests updated since `opts.Since` (the whole history if there is none)
+// along with all the open ones, the later ones only fetch the pull requests updated since the previous call
+// and merge them into the stored list. The list is extended back in time if an earlier Since is asked for.
+// The result is then narrowed down to `opts` locally.
+// Without Since, a limit or Until can't be served by a part of the history, so if there is no whole one yet
+// it falls back to Pulls rather than downloading all of it for a few PRs
+func SyncPulls(ctx context.Context, a github.API, c cache.Cache, opts github.PullRequestsOptions) ([]github.PullRequest, error) {
+	list := pullList{}
+	if _, err := c.Get(pullsCacheKey, &list); err != nil {
+		reportFsError(errors.Wrap(err, "getting cache"))
+		list = pullList{}
+	}
+
+	empty := list.Synced.IsZero()
+	since := list.Synced
+	if !list.covers(opts.Since) {
+		if opts.Since.IsZero() && (opts.Limit > 0 || !opts.Until.IsZero()) {
+			return Pulls(ctx, a, opts)
+		}
+		since = opts.Since
+	}
+
+	fresh, err := a.PullRequests(ctx, github.PullRequestsOptions{
+		State:        github.StateAll,
+		UpdatedSince: since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if empty && !since.IsZero() {
+		// the idle open PRs aren't updated since then, but they are in the window anyway
+		open, err := a.PullRequests(ctx, github.PullRequestsOptions{State: github.StateOpen})
+		if err != nil {
+			return nil, err
+		}
+		fresh = append(fresh, open...)
+	}
+
+	list, changed := mergePulls(list, fresh)
+	if empty {
+		list.From = since
+	} else if since.Before(list.From) {
+		list.From = since
+		changed = true
+	}
+	if changed {
+		if err := c.Set(pullsCacheKey, list); err != nil {
+			reportFsError(errors.Wrap(err, "setting cache"))
+		}
+	}
+
+	prs := []github.PullRequest{}
+	for _, p := range list.Pulls {
+		if opts.State == github.StateAll || p.State == opts.State {
+			prs = append(prs, p)
+		}
+	}
+	// the order the API would list them in
+	sort.SliceStable(prs, func(i, j int) bool {
+		if !opts.Since.IsZero() {
+			return prs[i].UpdatedAt.After(prs[j].UpdatedAt)
+		}
+		return prs[i].CreatedAt.After(prs[j].CreatedAt)
+	})
+
+	return opts.Apply(prs), nil
+}
+
+// mergePulls replaces the known pull requests with their fresh versions and adds the new ones.
+// It also tells if anything has changed: the PRs which weren't updated since they are known don't count
+func mergePulls(list pullList, fresh []github.PullRequest) (pullList, bool) {
+	index := map[int]int{}
+	for i, p := range list.Pulls {
+		index[p.Number] = i
+	}
+
+	changed := false
+	for _, p := range fresh {
+		if i, ok := index[p.Number]; !ok {
+			index[p.Number] = len(list.Pulls)
+			list.Pulls = append(list.Pulls, p)
+			changed = true
+		} else if p.UpdatedAt.After(list.Pulls[i].UpdatedAt) {
+			list.Pulls[i] = p
+			changed = true
+		}
+
+		if p.UpdatedAt.After(list.Synced) {
+			list.Synced = p.UpdatedAt
+		}
+	}
+
+	return list, changed
+}
+
+// FillDetails calls .FillDetails for each PR in prs to fill the given `details`
+// using `concurrency` workers in parallel.
+// It returns a channel which receives a value per processed PR and is closed once all
+// the work is done. After the first error no more PRs are picked up and the ones in progress are cancelled,
+// so the caller should stop reading once it gets an error or the channel is closed.
+// Cancelling `ctx` stops picking up PRs as well and sends ctx.Err() to the channel.
+// Only closed PRs are cached since open ones still may change.
+// A cached PR is ignored if it's been updated since (e.g. commented on after closing)
 func FillDetails(
+	ctx context.Context,
 	a github.API,
 	c cache.Cache,
 	prs []github.PullRequest,
-) chan error {
+	details []github.Detail,
+	concurrency int,
+) <-chan error {
 	ch := make(chan error, len(prs))
 
-	for i, p := range prs {
-		go (func(i int, p github.PullRequest) {
-			var err error
+	if len(details) == 0 {
+		for range prs {
+			ch <- nil
+		}
+		close(ch)
+		return ch
+	}
 
-			cacheKey := fmt.Sprintf("pr%d", p.Number)
-			found, err := c.Get(cacheKey, &p)
-			if err != nil {
-				reportFsError(errors.Wrap(err, "getting cache"))
-			}
+	if concurrency < 1 {
+		concurrency = 1
+	}
 
-			if !found {
-				err = p.FillDetails(a)
-			}
+	ctx, cancel := context.WithCancel(ctx)
+	jobs := make(chan int)
+	failed := make(chan struct{})
+	var failOnce sync.Once
+	var wg sync.WaitGroup
 
-			if err != nil {
-				ch <- err
-			} else {
-				// since p is a copy of i-th elem, we explicitly assign it to prs[i] to make the actual change
-				prs[i] = p
-				if err := c.Set(cacheKey, p); err != nil {
-					reportFsError(errors.Wrap(err, "setting cache"))
+	// interrupted reports the cancellation of `ctx` unless it's been cancelled because of an error
+	interrupted := func() {
+		select {
+		case <-failed:
+		default:
+			ch <- ctx.Err()
+		}
+	}
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				err := fillDetails(ctx, a, c, prs, i, details)
+				if err != nil {
+					failOnce.Do(func() {
+						close(failed)
+						cancel()
+					})
 				}
-				ch <- nil
+				ch <- err
 			}
-		})(i, p)
+		}()
 	}
 
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Wait()
+			cancel()
+			close(ch)
+		}()
+
+		for i := range prs {
+			select {
+			case <-failed:
+				return
+			case <-ctx.Done():
+				interrupted()
+				return
+			default:
+			}
+
+			select {
+			case jobs <- i:
+			case <-failed:
+				return
+			case <-ctx.Done():
+				interrupted()
+				return
+			}
+		}
+	}()
+
 	return ch
 }
 
+// fillDetails fills the details of the i-th PR using the cache when possible
+func fillDetails(
+	ctx context.Context,
+	a github.API,
+	c cache.Cache,
+	prs []github.PullRequest,
+	i int,
+	details []github.Detail,
+) error {
+	p := prs[i]
+
+	cacheKey := fmt.Sprintf("pr%d", p.Number)
+	if !p.IsOpen() {
+		cached := p
+		found, err := c.Get(cacheKey, &cached)
+		if err != nil {
+			reportFsError(errors.Wrap(err, "getting cache"))
+		} else if found && !cached.UpdatedAt.Before(p.UpdatedAt) {
+			p = cached
+		}
+	}
+
+	// the cached entry might be lacking some details if it was written
+	// for another set of metrics or before some detail was introduced
+	fetched := !p.HasDetails(details)
+	if err := p.FillDetails(ctx, a, details); err != nil {
+		return err
+	}
+
+	// since p is a copy of i-th elem, we explicitly assign it to prs[i] to make the actual change
+	prs[i] = p
+	if fetched && !p.IsOpen() {
+		if err := c.Set(cacheKey, p); err != nil {
+			reportFsError(errors.Wrap(err, "setting cache"))
+		}
+	}
+
+	return nil
+}
+
 func reportFsError(err error) {
 	log.Printf("File system error occurred while accessing the cache: %s\n", err)
 }
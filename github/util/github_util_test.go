@@ -1,8 +1,13 @@
 package util
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/kirillrogovoy/pullkee/github"
 	"github.com/stretchr/testify/require"
@@ -20,7 +25,7 @@ func TestPulls(t *testing.T) {
 	t.Run("Works when the requests are successful", func(t *testing.T) {
 		a := apiMock{}
 
-		pulls, err := Pulls(a, 0)
+		pulls, err := Pulls(context.Background(), a, github.PullRequestsOptions{State: github.StateClosed})
 
 		require.Equal(t, pullsFromAPI, pulls)
 		require.Nil(t, err)
@@ -31,7 +36,149 @@ func TestPulls(t *testing.T) {
 			err: fmt.Errorf("Network failed"),
 		}
 
-		_, err := Pulls(a, 0)
+		_, err := Pulls(context.Background(), a, github.PullRequestsOptions{State: github.StateClosed})
+
+		require.EqualError(t, err, "Network failed")
+	})
+}
+
+func TestSyncPulls(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2018, 4, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("Fetches the whole history first and then only the updates", func(t *testing.T) {
+		c := jsonCacheMock{}
+		calls := []github.PullRequestsOptions{}
+		a := syncAPIMock{calls: &calls, pulls: []github.PullRequest{
+			{Number: 2, State: github.StateOpen, CreatedAt: day(2), UpdatedAt: day(3)},
+			{Number: 1, State: github.StateClosed, CreatedAt: day(1), UpdatedAt: day(2)},
+		}}
+
+		pulls, err := SyncPulls(context.Background(), a, c, github.PullRequestsOptions{State: github.StateAll})
+		require.Nil(t, err)
+		require.Equal(t, []int{2, 1}, numbers(pulls))
+		require.Equal(t, github.PullRequestsOptions{State: github.StateAll}, calls[0])
+
+		// #2 gets merged and #3 appears
+		a.pulls = []github.PullRequest{
+			{Number: 3, State: github.StateOpen, CreatedAt: day(4), UpdatedAt: day(5)},
+			{Number: 2, State: github.StateClosed, CreatedAt: day(2), UpdatedAt: day(4)},
+		}
+
+		pulls, err = SyncPulls(context.Background(), a, c, github.PullRequestsOptions{State: github.StateClosed})
+		require.Nil(t, err)
+		require.Equal(t, []int{2, 1}, numbers(pulls))
+		require.Equal(t, github.PullRequestsOptions{State: github.StateAll, UpdatedSince: day(3)}, calls[1])
+
+		pulls, err = SyncPulls(context.Background(), a, c, github.PullRequestsOptions{State: github.StateAll, Limit: 2})
+		require.Nil(t, err)
+		require.Equal(t, []int{3, 2}, numbers(pulls))
+		require.Equal(t, github.PullRequestsOptions{State: github.StateAll, UpdatedSince: day(5)}, calls[2])
+	})
+
+	t.Run("Only fetches what's asked for if there is no whole history for a limit", func(t *testing.T) {
+		c := jsonCacheMock{}
+		calls := []github.PullRequestsOptions{}
+		a := syncAPIMock{calls: &calls, pulls: []github.PullRequest{
+			{Number: 2, State: github.StateClosed, CreatedAt: day(2), ClosedAt: day(3), UpdatedAt: day(3)},
+			{Number: 1, State: github.StateClosed, CreatedAt: day(1), ClosedAt: day(2), UpdatedAt: day(2)},
+		}}
+
+		opts := github.PullRequestsOptions{State: github.StateClosed, Limit: 1, Until: day(5)}
+		pulls, err := SyncPulls(context.Background(), a, c, opts)
+		require.Nil(t, err)
+		require.Equal(t, []int{2}, numbers(pulls))
+		require.Equal(t, []github.PullRequestsOptions{opts}, calls)
+		require.Len(t, c, 0)
+	})
+
+	t.Run("Builds the list since the window and extends it back on demand", func(t *testing.T) {
+		c := jsonCacheMock{}
+		calls := []github.PullRequestsOptions{}
+		a := syncAPIMock{calls: &calls, pulls: []github.PullRequest{
+			{Number: 4, State: github.StateClosed, CreatedAt: day(3), ClosedAt: day(4), UpdatedAt: day(4)},
+			{Number: 3, State: github.StateOpen, CreatedAt: day(1), UpdatedAt: day(1)},
+			{Number: 2, State: github.StateClosed, CreatedAt: day(2), ClosedAt: day(3), UpdatedAt: day(3)},
+			{Number: 1, State: github.StateClosed, CreatedAt: day(1), ClosedAt: day(2), UpdatedAt: day(2)},
+		}}
+
+		pulls, err := SyncPulls(context.Background(), a, c, github.PullRequestsOptions{State: github.StateAll, Since: day(3)})
+		require.Nil(t, err)
+		require.Equal(t, []int{4, 2, 3}, numbers(pulls))
+		require.Equal(t, []github.PullRequestsOptions{
+			{State: github.StateAll, UpdatedSince: day(3)},
+			{State: github.StateOpen},
+		}, calls)
+
+		// a later window is served by the updates
+		pulls, err = SyncPulls(context.Background(), a, c, github.PullRequestsOptions{State: github.StateClosed, Since: day(4)})
+		require.Nil(t, err)
+		require.Equal(t, []int{4}, numbers(pulls))
+		require.Equal(t, github.PullRequestsOptions{State: github.StateAll, UpdatedSince: day(4)}, calls[2])
+
+		// an earlier one needs the older PRs
+		pulls, err = SyncPulls(context.Background(), a, c, github.PullRequestsOptions{State: github.StateClosed, Since: day(2)})
+		require.Nil(t, err)
+		require.Equal(t, []int{4, 2, 1}, numbers(pulls))
+		require.Equal(t, github.PullRequestsOptions{State: github.StateAll, UpdatedSince: day(2)}, calls[3])
+		require.Len(t, calls, 4)
+	})
+
+	t.Run("Only rewrites the list when something has changed", func(t *testing.T) {
+		sets := 0
+		c := countingCacheMock{jsonCacheMock: jsonCacheMock{}, sets: &sets}
+		a := syncAPIMock{calls: &[]github.PullRequestsOptions{}, pulls: []github.PullRequest{
+			{Number: 1, State: github.StateOpen, CreatedAt: day(1), UpdatedAt: day(2)},
+		}}
+
+		for i := 0; i < 2; i++ {
+			_, err := SyncPulls(context.Background(), a, c, github.PullRequestsOptions{State: github.StateAll})
+			require.Nil(t, err)
+		}
+		require.Equal(t, 1, sets)
+
+		a.pulls[0].UpdatedAt = day(3)
+		pulls, err := SyncPulls(context.Background(), a, c, github.PullRequestsOptions{State: github.StateAll})
+		require.Nil(t, err)
+		require.Equal(t, day(3), pulls[0].UpdatedAt)
+		require.Equal(t, 2, sets)
+	})
+
+	t.Run("Lists by the update time if there is Since", func(t *testing.T) {
+		c := jsonCacheMock{}
+		a := syncAPIMock{calls: &[]github.PullRequestsOptions{}, pulls: []github.PullRequest{
+			{Number: 2, State: github.StateOpen, CreatedAt: day(2), UpdatedAt: day(3)},
+			{Number: 1, State: github.StateOpen, CreatedAt: day(1), UpdatedAt: day(4)},
+		}}
+
+		_, err := SyncPulls(context.Background(), a, c, github.PullRequestsOptions{State: github.StateAll})
+		require.Nil(t, err)
+
+		pulls, err := SyncPulls(context.Background(), a, c, github.PullRequestsOptions{
+			State: github.StateOpen,
+			Since: day(1),
+		})
+		require.Nil(t, err)
+		require.Equal(t, []int{1, 2}, numbers(pulls))
+	})
+
+	t.Run("Works even when had a cache read error", func(t *testing.T) {
+		a := syncAPIMock{calls: &[]github.PullRequestsOptions{}, pulls: pullsFromAPI}
+		c := newCacheMock()
+		c.getErr = fmt.Errorf("Nasty cache error")
+
+		pulls, err := SyncPulls(context.Background(), a, c, github.PullRequestsOptions{State: github.StateAll})
+		require.Nil(t, err)
+		require.Equal(t, pullsFromAPI, pulls)
+	})
+
+	t.Run("Fails when couldn't fetch the updates", func(t *testing.T) {
+		a := apiMock{
+			err: fmt.Errorf("Network failed"),
+		}
+
+		_, err := SyncPulls(context.Background(), a, jsonCacheMock{}, github.PullRequestsOptions{State: github.StateAll})
 
 		require.EqualError(t, err, "Network failed")
 	})
@@ -50,9 +197,8 @@ func TestFillDetails(t *testing.T) {
 		c := newCacheMock()
 
 		var err error
-		ch := FillDetails(a, c, prs)
-		for range prs {
-			e := <-ch
+		ch := FillDetails(context.Background(), a, c, prs, github.AllDetails, 2)
+		for e := range ch {
 			if e != nil {
 				err = e
 			}
@@ -80,9 +226,8 @@ func TestFillDetails(t *testing.T) {
 		c.getErr = fmt.Errorf("Nasty cache error")
 
 		var err error
-		ch := FillDetails(a, c, prs)
-		for range prs {
-			e := <-ch
+		ch := FillDetails(context.Background(), a, c, prs, github.AllDetails, 2)
+		for e := range ch {
 			if e != nil {
 				err = e
 			}
@@ -104,9 +249,8 @@ func TestFillDetails(t *testing.T) {
 		c.setErr = fmt.Errorf("Nasty cache error")
 
 		var err error
-		ch := FillDetails(a, c, prs)
-		for range prs {
-			e := <-ch
+		ch := FillDetails(context.Background(), a, c, prs, github.AllDetails, 2)
+		for e := range ch {
 			if e != nil {
 				err = e
 			}
@@ -118,6 +262,78 @@ func TestFillDetails(t *testing.T) {
 		require.Equal(t, 100, *prs[0].DiffSize)
 	})
 
+	t.Run("Ignores the cached PR if it's been updated since", func(t *testing.T) {
+		updatedAt := time.Date(2018, 4, 2, 0, 0, 0, 0, time.UTC)
+		size := 50
+		c := jsonCacheMock{}
+		require.Nil(t, c.Set("pr1", github.PullRequest{Number: 1, UpdatedAt: updatedAt, DiffSize: &size}))
+
+		prs := []github.PullRequest{{Number: 1, UpdatedAt: updatedAt}}
+		for e := range FillDetails(context.Background(), apiMock{}, c, prs, []github.Detail{github.DetailDiffSize}, 1) {
+			require.Nil(t, e)
+		}
+		require.Equal(t, 50, *prs[0].DiffSize)
+
+		prs = []github.PullRequest{{Number: 1, UpdatedAt: updatedAt.Add(time.Hour)}}
+		for e := range FillDetails(context.Background(), apiMock{}, c, prs, []github.Detail{github.DetailDiffSize}, 1) {
+			require.Nil(t, e)
+		}
+		require.Equal(t, 100, *prs[0].DiffSize)
+
+		cached := github.PullRequest{}
+		_, err := c.Get("pr1", &cached)
+		require.Nil(t, err)
+		require.Equal(t, 100, *cached.DiffSize)
+	})
+
+	t.Run("Doesn't cache open PRs", func(t *testing.T) {
+		prs := []github.PullRequest{
+			{Number: 1, State: github.StateOpen},
+			{Number: 2, State: github.StateClosed},
+		}
+
+		a := apiMock{}
+		c := newCacheMock()
+
+		var err error
+		ch := FillDetails(context.Background(), a, c, prs, github.AllDetails, 2)
+		for e := range ch {
+			if e != nil {
+				err = e
+			}
+		}
+
+		require.Nil(t, err)
+		require.Equal(t, 100, *prs[0].DiffSize)
+		_, cached := c.store["pr1"]
+		require.False(t, cached)
+		_, cached = c.store["pr2"]
+		require.True(t, cached)
+	})
+
+	t.Run("Doesn't make any requests if no details are needed", func(t *testing.T) {
+		prs := []github.PullRequest{
+			{Number: 1},
+		}
+
+		a := apiMock{
+			err: fmt.Errorf("Should not be called"),
+		}
+		c := newCacheMock()
+
+		var err error
+		ch := FillDetails(context.Background(), a, c, prs, []github.Detail{}, 2)
+		for e := range ch {
+			if e != nil {
+				err = e
+			}
+		}
+
+		require.Nil(t, err)
+		require.Nil(t, prs[0].DiffSize)
+		require.Len(t, c.store, 0)
+	})
+
 	t.Run("Fails when couldn't fetch details", func(t *testing.T) {
 		prs := []github.PullRequest{
 			{Number: 1},
@@ -130,9 +346,8 @@ func TestFillDetails(t *testing.T) {
 		c := newCacheMock()
 
 		var err error
-		ch := FillDetails(a, c, prs)
-		for range prs {
-			e := <-ch
+		ch := FillDetails(context.Background(), a, c, prs, github.AllDetails, 2)
+		for e := range ch {
 			if e != nil {
 				err = e
 			}
@@ -140,6 +355,62 @@ func TestFillDetails(t *testing.T) {
 
 		require.EqualError(t, err, "diff size: Fetching error")
 	})
+
+	t.Run("Stops picking up PRs after an error", func(t *testing.T) {
+		prs := []github.PullRequest{}
+		for i := 1; i <= 20; i++ {
+			prs = append(prs, github.PullRequest{Number: i})
+		}
+
+		calls := int32(0)
+		a := apiMock{
+			err:   fmt.Errorf("Fetching error"),
+			calls: &calls,
+		}
+
+		errs := 0
+		for e := range FillDetails(context.Background(), a, newCacheMock(), prs, github.AllDetails, 2) {
+			require.NotNil(t, e)
+			errs++
+		}
+
+		require.True(t, errs >= 1 && errs <= 3, fmt.Sprintf("Expected 1-3 errors, got %d", errs))
+		require.Equal(t, int32(errs), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Cancels the PRs in progress after an error", func(t *testing.T) {
+		prs := []github.PullRequest{{Number: 1}, {Number: 2}}
+		a := blockingAPIMock{started: make(chan struct{})}
+
+		errs := []string{}
+		for e := range FillDetails(context.Background(), a, newCacheMock(), prs, github.AllDetails, 2) {
+			errs = append(errs, e.Error())
+		}
+
+		require.Len(t, errs, 2)
+		require.Contains(t, errs, "diff size: Fetching error")
+		require.Contains(t, errs, "diff size: context canceled")
+	})
+
+	t.Run("Doesn't pick up PRs once the context is cancelled", func(t *testing.T) {
+		prs := []github.PullRequest{{Number: 1}, {Number: 2}}
+
+		calls := int32(0)
+		a := apiMock{
+			calls: &calls,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		errs := []error{}
+		for e := range FillDetails(ctx, a, newCacheMock(), prs, github.AllDetails, 2) {
+			errs = append(errs, e)
+		}
+
+		require.Equal(t, []error{context.Canceled}, errs)
+		require.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	})
 }
 
 type cacheMock struct {
@@ -147,11 +418,13 @@ type cacheMock struct {
 	found  bool
 	getErr error
 	setErr error
+	mu     *sync.Mutex
 }
 
 func newCacheMock() cacheMock {
 	c := cacheMock{}
 	c.store = map[string]interface{}{}
+	c.mu = &sync.Mutex{}
 	return c
 }
 
@@ -160,6 +433,8 @@ func (c cacheMock) Set(key string, target interface{}) error {
 		return c.setErr
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.store[key] = target
 	return nil
 }
@@ -176,38 +451,166 @@ func (c cacheMock) Get(key string, target interface{}) (bool, error) {
 	return c.found, c.getErr
 }
 
+func (c cacheMock) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.store, key)
+	return nil
+}
+
+func (c cacheMock) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.store {
+		delete(c.store, key)
+	}
+	return nil
+}
+
+func (c cacheMock) Keys() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := []string{}
+	for key := range c.store {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// jsonCacheMock is an in-memory cache.Cache which stores the values as JSON like the real one
+type jsonCacheMock map[string][]byte
+
+func (c jsonCacheMock) Set(key string, x interface{}) error {
+	data, err := json.Marshal(x)
+	c[key] = data
+	return err
+}
+
+func (c jsonCacheMock) Get(key string, x interface{}) (bool, error) {
+	data, ok := c[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, x)
+}
+
+func (c jsonCacheMock) Delete(key string) error {
+	delete(c, key)
+	return nil
+}
+
+func (c jsonCacheMock) Clear() error {
+	for key := range c {
+		delete(c, key)
+	}
+	return nil
+}
+
+func (c jsonCacheMock) Keys() ([]string, error) {
+	keys := []string{}
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// countingCacheMock is a jsonCacheMock which counts the writes
+type countingCacheMock struct {
+	jsonCacheMock
+	sets *int
+}
+
+func (c countingCacheMock) Set(key string, x interface{}) error {
+	*c.sets++
+	return c.jsonCacheMock.Set(key, x)
+}
+
+// syncAPIMock returns `pulls` of the requested state which were updated since the requested time and records the options
+type syncAPIMock struct {
+	apiMock
+	pulls []github.PullRequest
+	calls *[]github.PullRequestsOptions
+}
+
+func (a syncAPIMock) PullRequests(ctx context.Context, opts github.PullRequestsOptions) ([]github.PullRequest, error) {
+	*a.calls = append(*a.calls, opts)
+
+	prs := []github.PullRequest{}
+	for _, p := range a.pulls {
+		if opts.State == github.StateAll || p.State == opts.State {
+			prs = append(prs, p)
+		}
+	}
+	return opts.Apply(prs), nil
+}
+
+// blockingAPIMock blocks on the diff size of the PR #2 until the context is done
+// and fails to get the one of the PR #1 once the PR #2 is in progress
+type blockingAPIMock struct {
+	apiMock
+	started chan struct{}
+}
+
+func (a blockingAPIMock) DiffSize(ctx context.Context, number int) (int, error) {
+	if number == 1 {
+		<-a.started
+		return 0, fmt.Errorf("Fetching error")
+	}
+	close(a.started)
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func numbers(prs []github.PullRequest) []int {
+	n := []int{}
+	for _, p := range prs {
+		n = append(n, p.Number)
+	}
+	return n
+}
+
 type apiMock struct {
-	err error
+	err   error
+	calls *int32
 }
 
-func (a apiMock) ClosedPullRequests(limit int) ([]github.PullRequest, error) {
+func (a apiMock) PullRequests(ctx context.Context, opts github.PullRequestsOptions) ([]github.PullRequest, error) {
 	if a.err != nil {
 		return nil, a.err
 	}
 	return pullsFromAPI, nil
 }
 
-func (a apiMock) Get(url string, target interface{}) error {
+func (a apiMock) Get(ctx context.Context, url string, target interface{}) error {
 	panic("not implemented")
 }
 
-func (a apiMock) Repository() (*github.Repository, error) {
+func (a apiMock) Repository(ctx context.Context) (*github.Repository, error) {
 	panic("not implemented")
 }
 
-func (a apiMock) DiffSize(number int) (int, error) {
+func (a apiMock) DiffSize(ctx context.Context, number int) (int, error) {
+	if a.calls != nil {
+		atomic.AddInt32(a.calls, 1)
+	}
 	if a.err != nil {
 		return 0, a.err
 	}
 	return 100, nil
 }
 
-func (a apiMock) Comments(number int) ([]github.Comment, error) {
+func (a apiMock) Comments(ctx context.Context, number int) ([]github.Comment, error) {
 	return []github.Comment{{Body: "Neat!"}}, nil
 }
 
-func (a apiMock) ReviewRequests(number int) ([]github.User, error) {
+func (a apiMock) ReviewRequests(ctx context.Context, number int) ([]github.User, error) {
 	return []github.User{{
 		Login: "User1",
 	}}, nil
 }
+
+func (a apiMock) Reviews(ctx context.Context, number int) ([]github.Review, error) {
+	return []github.Review{{
+		User: github.User{Login: "User1"},
+	}}, nil
+}
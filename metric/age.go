@@ -12,6 +12,11 @@ func (m *Age) Description() string {
 	return "What is the average age of the PR for the particular author?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *Age) Details() []github.Detail {
+	return nil
+}
+
 // Calculate the average age of a PR in total and by developer
 func (m *Age) Calculate(pullRequests []github.PullRequest) error {
 	a := averageMap{}
@@ -34,3 +39,8 @@ func (m *Age) Calculate(pullRequests []github.PullRequest) error {
 func (m *Age) String() string {
 	return m.average.string("days")
 }
+
+// Result returns the calculated data in a structured form
+func (m *Age) Result() Result {
+	return m.average.result("days")
+}
@@ -12,6 +12,11 @@ func (m *AgeAssignee) Description() string {
 	return "What is the average age of the PR when one is the assignee?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *AgeAssignee) Details() []github.Detail {
+	return nil
+}
+
 // Calculate the average age of a PR in total and by developer
 func (m *AgeAssignee) Calculate(pullRequests []github.PullRequest) error {
 	a := averageMap{}
@@ -36,3 +41,8 @@ func (m *AgeAssignee) Calculate(pullRequests []github.PullRequest) error {
 func (m *AgeAssignee) String() string {
 	return m.average.string("days")
 }
+
+// Result returns the calculated data in a structured form
+func (m *AgeAssignee) Result() Result {
+	return m.average.result("days")
+}
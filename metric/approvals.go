@@ -0,0 +1,43 @@
+package metric
+
+import "github.com/kirillrogovoy/pullkee/github"
+
+// Approvals contains the calculated data
+type Approvals struct {
+	counter counterMap
+}
+
+// Description of the metric
+func (m *Approvals) Description() string {
+	return "How many PRs did one approve?"
+}
+
+// Details the metric needs to be filled in the PRs
+func (m *Approvals) Details() []github.Detail {
+	return []github.Detail{github.DetailReviews}
+}
+
+// Calculate how many PRs each developer approved
+func (m *Approvals) Calculate(pullRequests []github.PullRequest) error {
+	m.counter = counterMap{}
+	for _, pr := range pullRequests {
+		for _, approver := range pr.Approvers() {
+			name := approver.Login
+			if _, ok := m.counter[name]; !ok {
+				m.counter[name] = &counter{name, 0}
+			}
+			m.counter[name].Count++
+		}
+	}
+	return nil
+}
+
+// Converts the calculated data to a string
+func (m *Approvals) String() string {
+	return m.counter.string()
+}
+
+// Result returns the calculated data in a structured form
+func (m *Approvals) Result() Result {
+	return m.counter.result("PRs")
+}
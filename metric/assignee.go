@@ -12,6 +12,11 @@ func (m *Assignee) Description() string {
 	return "How often one is the assignee?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *Assignee) Details() []github.Detail {
+	return nil
+}
+
 // Calculate how often each developer is an assignee
 func (m *Assignee) Calculate(pullRequests []github.PullRequest) error {
 	m.counter = counterMap{}
@@ -31,3 +36,8 @@ func (m *Assignee) Calculate(pullRequests []github.PullRequest) error {
 func (m *Assignee) String() string {
 	return m.counter.string()
 }
+
+// Result returns the calculated data in a structured form
+func (m *Assignee) Result() Result {
+	return m.counter.result("PRs")
+}
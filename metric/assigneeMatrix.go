@@ -17,6 +17,11 @@ func (m *AssigneeMatrix) Description() string {
 	return "How often does one developer pick another as an assignee?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *AssigneeMatrix) Details() []github.Detail {
+	return nil
+}
+
 // Calculate how often each developer is an assignee
 func (m *AssigneeMatrix) Calculate(pullRequests []github.PullRequest) error {
 	m.counter = map[string]map[string]*counter{}
@@ -63,3 +68,20 @@ func (m *AssigneeMatrix) String() string {
 
 	return result
 }
+
+// Result returns the calculated data in a structured form.
+// Values contain the number of assignments per author, Matrix breaks them down by assignee
+func (m *AssigneeMatrix) Result() Result {
+	r := Result{Unit: "PRs", Aggregation: Sum, Values: []Value{}, Matrix: map[string][]Value{}}
+
+	authors := counterMap{}
+	for author, assignees := range m.counter {
+		sub := counterMap(assignees).result("PRs")
+		r.Matrix[author] = sub.Values
+		r.Total += sub.Total
+		authors[author] = &counter{author, int(sub.Total)}
+	}
+	r.Values = authors.result("PRs").Values
+
+	return r
+}
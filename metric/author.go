@@ -12,6 +12,11 @@ func (m *Author) Description() string {
 	return "How many PRs did one create?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *Author) Details() []github.Detail {
+	return nil
+}
+
 // Calculate how often each developer is an author
 func (m *Author) Calculate(pullRequests []github.PullRequest) error {
 	m.counter = counterMap{}
@@ -29,3 +34,8 @@ func (m *Author) Calculate(pullRequests []github.PullRequest) error {
 func (m *Author) String() string {
 	return m.counter.string()
 }
+
+// Result returns the calculated data in a structured form
+func (m *Author) Result() Result {
+	return m.counter.result("PRs")
+}
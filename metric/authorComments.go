@@ -12,6 +12,11 @@ func (m *AuthorComments) Description() string {
 	return "How many comments does one have on his own PR?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *AuthorComments) Details() []github.Detail {
+	return []github.Detail{github.DetailComments}
+}
+
 // Calculate the data
 func (m *AuthorComments) Calculate(pullRequests []github.PullRequest) error {
 	a := averageMap{}
@@ -39,3 +44,8 @@ func (m *AuthorComments) Calculate(pullRequests []github.PullRequest) error {
 func (m *AuthorComments) String() string {
 	return m.average.string("comments")
 }
+
+// Result returns the calculated data in a structured form
+func (m *AuthorComments) Result() Result {
+	return m.average.result("comments")
+}
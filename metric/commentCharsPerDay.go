@@ -16,6 +16,11 @@ func (m *CommentCharsPerDay) Description() string {
 	return "How many chars/day of comments does one generate?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *CommentCharsPerDay) Details() []github.Detail {
+	return []github.Detail{github.DetailComments}
+}
+
 // Calculate the average age of a PR in total and by developer
 func (m *CommentCharsPerDay) Calculate(pullRequests []github.PullRequest) error {
 	a := averageMap{}
@@ -45,3 +50,8 @@ func (m *CommentCharsPerDay) Calculate(pullRequests []github.PullRequest) error
 func (m *CommentCharsPerDay) String() string {
 	return m.average.string("chars/day")
 }
+
+// Result returns the calculated data in a structured form
+func (m *CommentCharsPerDay) Result() Result {
+	return m.average.result("chars/day")
+}
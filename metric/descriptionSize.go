@@ -12,6 +12,11 @@ func (m *DescriptionSize) Description() string {
 	return "What is the average size of the Pull Request description?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *DescriptionSize) Details() []github.Detail {
+	return nil
+}
+
 // Calculate the data
 func (m *DescriptionSize) Calculate(pullRequests []github.PullRequest) error {
 	a := averageMap{}
@@ -33,3 +38,8 @@ func (m *DescriptionSize) Calculate(pullRequests []github.PullRequest) error {
 func (m *DescriptionSize) String() string {
 	return m.average.string("chars")
 }
+
+// Result returns the calculated data in a structured form
+func (m *DescriptionSize) Result() Result {
+	return m.average.result("chars")
+}
@@ -12,6 +12,11 @@ func (m *DiffSize) Description() string {
 	return "What is the average diff size of the PR?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *DiffSize) Details() []github.Detail {
+	return []github.Detail{github.DetailDiffSize}
+}
+
 // Calculate the average age of a PR in total and by developer
 func (m *DiffSize) Calculate(pullRequests []github.PullRequest) error {
 	a := averageMap{}
@@ -34,3 +39,8 @@ func (m *DiffSize) Calculate(pullRequests []github.PullRequest) error {
 func (m *DiffSize) String() string {
 	return m.average.string("bytes")
 }
+
+// Result returns the calculated data in a structured form
+func (m *DiffSize) Result() Result {
+	return m.average.result("bytes")
+}
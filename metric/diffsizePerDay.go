@@ -16,6 +16,11 @@ func (m *DiffSizePerDay) Description() string {
 	return "How many bytes/day of diffs does one generate?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *DiffSizePerDay) Details() []github.Detail {
+	return []github.Detail{github.DetailDiffSize}
+}
+
 // Calculate the average age of a PR in total and by developer
 func (m *DiffSizePerDay) Calculate(pullRequests []github.PullRequest) error {
 	a := averageMap{}
@@ -47,3 +52,8 @@ func (m *DiffSizePerDay) Calculate(pullRequests []github.PullRequest) error {
 func (m *DiffSizePerDay) String() string {
 	return m.average.string("bytes/day")
 }
+
+// Result returns the calculated data in a structured form
+func (m *DiffSizePerDay) Result() Result {
+	return m.average.result("bytes/day")
+}
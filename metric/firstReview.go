@@ -0,0 +1,86 @@
+package metric
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kirillrogovoy/pullkee/github"
+)
+
+// FirstReview contains the calculated data
+type FirstReview struct {
+	average averageList
+}
+
+// Description of the metric
+func (m *FirstReview) Description() string {
+	return "How long does a PR of the particular author wait for the first review or comment?"
+}
+
+// Details the metric needs to be filled in the PRs
+func (m *FirstReview) Details() []github.Detail {
+	return []github.Detail{github.DetailComments, github.DetailReviews}
+}
+
+// Calculate the average time to the first response in total and by author
+func (m *FirstReview) Calculate(pullRequests []github.PullRequest) error {
+	a := averageMap{}
+	a.reset()
+
+	for _, pr := range pullRequests {
+		var first time.Time
+		for _, at := range firstResponses(pr) {
+			if first.IsZero() || at.Before(first) {
+				first = at
+			}
+		}
+
+		if first.IsZero() {
+			continue
+		}
+
+		a.add(first.Sub(pr.CreatedAt).Hours(), pr.User.Login)
+	}
+
+	m.average = a.toList()
+	return nil
+}
+
+// Converts the calculated data to a string
+func (m *FirstReview) String() string {
+	return m.average.string("hours")
+}
+
+// Result returns the calculated data in a structured form
+func (m *FirstReview) Result() Result {
+	return m.average.result("hours")
+}
+
+// firstResponses returns the time of the first review or comment on the PR
+// for each user except the author of the PR and the bots (e.g. CI ones, which respond within seconds)
+func firstResponses(pr github.PullRequest) map[string]time.Time {
+	first := map[string]time.Time{}
+
+	respond := func(login string, at time.Time) {
+		if login == pr.User.Login || isBot(login) || at.IsZero() {
+			return
+		}
+		if prev, ok := first[login]; !ok || at.Before(prev) {
+			first[login] = at
+		}
+	}
+
+	for _, comment := range *pr.Comments {
+		respond(comment.User.Login, comment.CreatedAt)
+	}
+	for _, review := range *pr.Reviews {
+		respond(review.User.Login, review.SubmittedAt)
+	}
+
+	return first
+}
+
+// isBot tells if the login belongs to a Github App like "dependabot[bot]"
+func isBot(login string) bool {
+	return strings.HasSuffix(login, "[bot]")
+}
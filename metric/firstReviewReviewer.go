@@ -0,0 +1,43 @@
+package metric
+
+import "github.com/kirillrogovoy/pullkee/github"
+
+// FirstReviewReviewer contains the calculated data
+type FirstReviewReviewer struct {
+	average averageList
+}
+
+// Description of the metric
+func (m *FirstReviewReviewer) Description() string {
+	return "How long does it take for the particular reviewer to first respond to a PR?"
+}
+
+// Details the metric needs to be filled in the PRs
+func (m *FirstReviewReviewer) Details() []github.Detail {
+	return []github.Detail{github.DetailComments, github.DetailReviews}
+}
+
+// Calculate the average time to the first response in total and by reviewer
+func (m *FirstReviewReviewer) Calculate(pullRequests []github.PullRequest) error {
+	a := averageMap{}
+	a.reset()
+
+	for _, pr := range pullRequests {
+		for reviewer, at := range firstResponses(pr) {
+			a.add(at.Sub(pr.CreatedAt).Hours(), reviewer)
+		}
+	}
+
+	m.average = a.toList()
+	return nil
+}
+
+// Converts the calculated data to a string
+func (m *FirstReviewReviewer) String() string {
+	return m.average.string("hours")
+}
+
+// Result returns the calculated data in a structured form
+func (m *FirstReviewReviewer) Result() Result {
+	return m.average.result("hours")
+}
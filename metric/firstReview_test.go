@@ -0,0 +1,69 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kirillrogovoy/pullkee/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstResponses(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2018, 4, 1, hour, 0, 0, 0, time.UTC)
+	}
+	comment := func(login string, hour int) github.Comment {
+		return github.Comment{User: github.User{Login: login}, CreatedAt: at(hour)}
+	}
+	review := func(login string, submittedAt time.Time) github.Review {
+		return github.Review{User: github.User{Login: login}, SubmittedAt: submittedAt}
+	}
+
+	cases := []struct {
+		name     string
+		comments []github.Comment
+		reviews  []github.Review
+		expected map[string]time.Time
+	}{
+		{
+			name:     "Excludes the author",
+			comments: []github.Comment{comment("author", 1), comment("alice", 2)},
+			reviews:  []github.Review{review("author", at(3))},
+			expected: map[string]time.Time{"alice": at(2)},
+		},
+		{
+			name:     "Skips the pending reviews",
+			reviews:  []github.Review{review("alice", time.Time{}), review("bob", at(4))},
+			expected: map[string]time.Time{"bob": at(4)},
+		},
+		{
+			name:     "Takes the earliest response of every reviewer",
+			comments: []github.Comment{comment("alice", 5), comment("bob", 2)},
+			reviews:  []github.Review{review("alice", at(3)), review("bob", at(6))},
+			expected: map[string]time.Time{"alice": at(3), "bob": at(2)},
+		},
+		{
+			name:     "Excludes the bots",
+			comments: []github.Comment{comment("codecov[bot]", 1), comment("alice", 2)},
+			reviews:  []github.Review{review("dependabot[bot]", at(1))},
+			expected: map[string]time.Time{"alice": at(2)},
+		},
+		{
+			name:     "Returns nothing without responses",
+			expected: map[string]time.Time{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			comments, reviews := c.comments, c.reviews
+			pr := github.PullRequest{
+				User:     github.User{Login: "author"},
+				Comments: &comments,
+				Reviews:  &reviews,
+			}
+
+			require.Equal(t, c.expected, firstResponses(pr))
+		})
+	}
+}
@@ -2,28 +2,115 @@
 // different ways.
 package metric
 
-import "github.com/kirillrogovoy/pullkee/github"
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/kirillrogovoy/pullkee/github"
+)
 
 // Metric is a common interface for every metric in metric/*.go
 type Metric interface {
 	Description() string
+	Details() []github.Detail
 	Calculate(pullRequests []github.PullRequest) error
 	String() string
+	Result() Result
+}
+
+// registry assigns every metric a stable identifier to refer to it from outside.
+// The order is the order in which the metrics are reported
+var registry = []struct {
+	id  string
+	new func() Metric
+}{
+	{"age", func() Metric { return &Age{} }},
+	{"age-assignee", func() Metric { return &AgeAssignee{} }},
+	{"assignee", func() Metric { return &Assignee{} }},
+	{"review-request", func() Metric { return &ReviewRequest{} }},
+	{"diff-size", func() Metric { return &DiffSize{} }},
+	{"diff-size-per-day", func() Metric { return &DiffSizePerDay{} }},
+	{"author", func() Metric { return &Author{} }},
+	{"assignee-matrix", func() Metric { return &AssigneeMatrix{} }},
+	{"comment-chars-per-day", func() Metric { return &CommentCharsPerDay{} }},
+	{"author-comments", func() Metric { return &AuthorComments{} }},
+	{"description-size", func() Metric { return &DescriptionSize{} }},
+	{"open-age", func() Metric { return &OpenAge{} }},
+	{"first-review", func() Metric { return &FirstReview{} }},
+	{"first-review-reviewer", func() Metric { return &FirstReviewReviewer{} }},
+	{"approvals", func() Metric { return &Approvals{} }},
 }
 
 // Metrics returns the list of all available metrics
 func Metrics() []Metric {
-	return []Metric{
-		&Age{},
-		&AgeAssignee{},
-		&Assignee{},
-		&ReviewRequest{},
-		&DiffSize{},
-		&DiffSizePerDay{},
-		&Author{},
-		&AssigneeMatrix{},
-		&CommentCharsPerDay{},
-		&AuthorComments{},
-		&DescriptionSize{},
+	metrics := []Metric{}
+	for _, r := range registry {
+		metrics = append(metrics, r.new())
+	}
+	return metrics
+}
+
+// ID returns the stable identifier of the metric or an empty string if it's not registered
+func ID(m Metric) string {
+	for _, r := range registry {
+		if reflect.TypeOf(r.new()) == reflect.TypeOf(m) {
+			return r.id
+		}
+	}
+	return ""
+}
+
+// Details returns all the PR details needed by at least one of the metrics
+func Details(metrics []Metric) []github.Detail {
+	needed := map[github.Detail]bool{}
+	for _, m := range metrics {
+		for _, d := range m.Details() {
+			needed[d] = true
+		}
+	}
+
+	details := []github.Detail{}
+	for _, d := range github.AllDetails {
+		if needed[d] {
+			details = append(details, d)
+		}
+	}
+	return details
+}
+
+// Select returns the metrics with the identifiers from `include` (or all of them if it's empty)
+// except the ones from `exclude`. It fails on an unknown identifier
+func Select(include []string, exclude []string) ([]Metric, error) {
+	known := map[string]bool{}
+	for _, r := range registry {
+		known[r.id] = true
+	}
+
+	toSet := func(ids []string) (map[string]bool, error) {
+		set := map[string]bool{}
+		for _, id := range ids {
+			if !known[id] {
+				return nil, fmt.Errorf("Unknown metric %q", id)
+			}
+			set[id] = true
+		}
+		return set, nil
+	}
+
+	included, err := toSet(include)
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := toSet(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := []Metric{}
+	for _, r := range registry {
+		if (len(included) == 0 || included[r.id]) && !excluded[r.id] {
+			metrics = append(metrics, r.new())
+		}
 	}
+	return metrics, nil
 }
@@ -0,0 +1,45 @@
+package metric
+
+import (
+	"testing"
+
+	"github.com/kirillrogovoy/pullkee/github"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestID(t *testing.T) {
+	require.Equal(t, "assignee-matrix", ID(&AssigneeMatrix{}))
+	require.Equal(t, "", ID(nil))
+}
+
+func TestDetails(t *testing.T) {
+	require.Equal(t, []github.Detail{}, Details([]Metric{&Age{}, &Author{}}))
+	require.Equal(
+		t,
+		[]github.Detail{github.DetailDiffSize, github.DetailComments, github.DetailReviews},
+		Details([]Metric{&FirstReview{}, &DiffSize{}, &Approvals{}}),
+	)
+}
+
+func TestSelect(t *testing.T) {
+	t.Run("Returns all the metrics by default", func(t *testing.T) {
+		metrics, err := Select(nil, nil)
+
+		require.Nil(t, err)
+		require.Equal(t, Metrics(), metrics)
+	})
+
+	t.Run("Includes and excludes metrics in the original order", func(t *testing.T) {
+		metrics, err := Select([]string{"author", "age", "assignee"}, []string{"assignee"})
+
+		require.Nil(t, err)
+		require.Equal(t, []Metric{&Age{}, &Author{}}, metrics)
+	})
+
+	t.Run("Fails on an unknown metric", func(t *testing.T) {
+		_, err := Select(nil, []string{"age", "happiness"})
+
+		require.EqualError(t, err, `Unknown metric "happiness"`)
+	})
+}
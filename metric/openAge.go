@@ -0,0 +1,51 @@
+package metric
+
+import (
+	"time"
+
+	"github.com/kirillrogovoy/pullkee/github"
+)
+
+// OpenAge contains the calculated data
+type OpenAge struct {
+	average averageList
+}
+
+// Description of the metric
+func (m *OpenAge) Description() string {
+	return "How long have the PRs of the particular author been waiting? (open PRs only)"
+}
+
+// Details the metric needs to be filled in the PRs
+func (m *OpenAge) Details() []github.Detail {
+	return nil
+}
+
+// Calculate the average age of a currently open PR in total and by developer
+func (m *OpenAge) Calculate(pullRequests []github.PullRequest) error {
+	a := averageMap{}
+	a.reset()
+	now := time.Now()
+
+	for _, pr := range pullRequests {
+		if !pr.IsOpen() {
+			continue
+		}
+
+		delta := now.Sub(pr.CreatedAt).Hours() / 24
+		a.add(delta, pr.User.Login)
+	}
+
+	m.average = a.toList()
+	return nil
+}
+
+// Converts the calculated data to a string
+func (m *OpenAge) String() string {
+	return m.average.string("days")
+}
+
+// Result returns the calculated data in a structured form
+func (m *OpenAge) Result() Result {
+	return m.average.result("days")
+}
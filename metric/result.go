@@ -0,0 +1,69 @@
+package metric
+
+import (
+	"math"
+	"sort"
+)
+
+// Aggregation tells how the Total of a Result is derived from its Values
+type Aggregation string
+
+// Possible aggregations
+const (
+	Average Aggregation = "average"
+	Sum     Aggregation = "sum"
+)
+
+// Result is a structured representation of the calculated data of a metric
+type Result struct {
+	Unit        string             `json:"unit"`
+	Aggregation Aggregation        `json:"aggregation"`
+	Total       float64            `json:"total"`
+	Values      []Value            `json:"values"`
+	Matrix      map[string][]Value `json:"matrix,omitempty"`
+	Stats       *Stats             `json:"stats,omitempty"` // distribution of the values of all the devs together
+}
+
+// Value is a value of a metric for the particular developer
+type Value struct {
+	Developer string  `json:"developer"`
+	Value     float64 `json:"value"`
+	Stats     *Stats  `json:"stats,omitempty"`
+}
+
+func (a averageList) result(unit string) Result {
+	sort.Sort(sort.Reverse(a))
+
+	r := Result{Unit: unit, Aggregation: Average, Values: []Value{}}
+	for _, dev := range a {
+		v := finite(dev.Value)
+		r.Total += v
+		r.Values = append(r.Values, Value{dev.Name, v, dev.Stats})
+	}
+
+	if len(a) > 0 {
+		r.Total /= float64(len(a))
+	}
+	r.Stats = a.stats()
+
+	return r
+}
+
+func (c counterMap) result(unit string) Result {
+	r := Result{Unit: unit, Aggregation: Sum, Values: []Value{}}
+	for _, i := range c.sorted() {
+		r.Total += float64(i.Count)
+		r.Values = append(r.Values, Value{Developer: i.Name, Value: float64(i.Count)})
+	}
+
+	return r
+}
+
+// finite replaces NaN and Inf (e.g. an average over zero days) with zero
+// since they can't be represented in most of the output formats
+func finite(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
@@ -12,6 +12,11 @@ func (m *ReviewRequest) Description() string {
 	return "How often one is requested for a review?"
 }
 
+// Details the metric needs to be filled in the PRs
+func (m *ReviewRequest) Details() []github.Detail {
+	return []github.Detail{github.DetailReviewRequests}
+}
+
 // Calculate the data
 func (m *ReviewRequest) Calculate(pullRequests []github.PullRequest) error {
 	m.counter = counterMap{}
@@ -31,3 +36,8 @@ func (m *ReviewRequest) Calculate(pullRequests []github.PullRequest) error {
 func (m *ReviewRequest) String() string {
 	return m.counter.string()
 }
+
+// Result returns the calculated data in a structured form
+func (m *ReviewRequest) Result() Result {
+	return m.counter.result("requests")
+}
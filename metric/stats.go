@@ -0,0 +1,63 @@
+package metric
+
+import (
+	"math"
+	"sort"
+)
+
+// Stats describes the distribution of the values an average is calculated from
+type Stats struct {
+	Count  int     `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P75    float64 `json:"p75"`
+	P90    float64 `json:"p90"`
+	P95    float64 `json:"p95"`
+	StdDev float64 `json:"stddev"`
+}
+
+// newStats calculates the statistics of `values`. It returns nil if there are no values
+func newStats(values []float64) *Stats {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := sum / float64(len(sorted))
+
+	variance := 0.0
+	for _, v := range sorted {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(sorted))
+
+	return &Stats{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   mean,
+		Median: percentile(sorted, 50),
+		P75:    percentile(sorted, 75),
+		P90:    percentile(sorted, 90),
+		P95:    percentile(sorted, 95),
+		StdDev: math.Sqrt(variance),
+	}
+}
+
+// percentile returns the p-th percentile of the sorted values using linear interpolation
+// between the closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,68 @@
+package metric
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStats(t *testing.T) {
+	t.Run("Calculates the distribution", func(t *testing.T) {
+		s := newStats([]float64{10, 1, 4, 2, 3, 5, 6, 7, 8, 9, 200})
+
+		require.Equal(t, 11, s.Count)
+		require.Equal(t, 1.0, s.Min)
+		require.Equal(t, 200.0, s.Max)
+		require.Equal(t, 23.181818181818183, s.Mean)
+		require.Equal(t, 6.0, s.Median)
+		require.Equal(t, 8.5, s.P75)
+		require.Equal(t, 10.0, s.P90)
+		require.Equal(t, 105.0, s.P95)
+		require.True(t, math.Abs(s.StdDev-55.9818) < 0.0001)
+	})
+
+	t.Run("Works with a single value", func(t *testing.T) {
+		s := newStats([]float64{42})
+
+		require.Equal(t, &Stats{
+			Count:  1,
+			Min:    42,
+			Max:    42,
+			Mean:   42,
+			Median: 42,
+			P75:    42,
+			P90:    42,
+			P95:    42,
+		}, s)
+	})
+
+	t.Run("Returns nil if there are no values", func(t *testing.T) {
+		require.Nil(t, newStats(nil))
+	})
+}
+
+func TestAverageList(t *testing.T) {
+	a := averageMap{}
+	a.reset()
+	a.add(1, "User1")
+	a.add(3, "User1")
+	a.add(200, "User1")
+	a.add(2, "User2")
+
+	l := a.toList()
+	require.Equal(
+		t,
+		"Total average: 35.00 days (median 2.50, p90 140.90)\n"+
+			"Average for User1: 68.00 days (median 3.00, p90 160.60)\n"+
+			"Average for User2: 2.00 days (median 2.00, p90 2.00)\n",
+		l.string("days"),
+	)
+
+	r := l.result("days")
+	require.Equal(t, 4, r.Stats.Count)
+	require.Equal(t, 3.0, r.Values[0].Stats.Median)
+
+	a.setCount(10)
+	require.Equal(t, "Total average: 10.30 days/day\nAverage for User1: 20.40 days/day\nAverage for User2: 0.20 days/day\n", a.toList().string("days/day"))
+}
@@ -7,8 +7,10 @@ import (
 
 // average is a helper for calculating an average value of something
 type average struct {
-	Sum   float64
-	Count int
+	Sum    float64
+	Count  int
+	Values []float64
+	rate   bool // Count is overridden, so Values don't form a meaningful distribution
 }
 
 // Calculate divides Sum by Count
@@ -23,7 +25,12 @@ type averageMap map[string]*average
 func (a *averageMap) toList() averageList {
 	var d averageList
 	for name, average := range *a {
-		d = append(d, averageItem{name, average.Calculate()})
+		item := averageItem{Name: name, Value: average.Calculate()}
+		if !average.rate {
+			item.values = average.Values
+			item.Stats = newStats(average.Values)
+		}
+		d = append(d, item)
 	}
 	return d
 }
@@ -39,11 +46,13 @@ func (a *averageMap) add(value float64, dev string) {
 	av := (*a)[dev]
 	av.Sum += value
 	av.Count++
+	av.Values = append(av.Values, value)
 }
 
 func (a *averageMap) setCount(i int) {
 	for key := range *a {
 		(*a)[key].Count = i
+		(*a)[key].rate = true
 	}
 }
 
@@ -69,8 +78,10 @@ func (a *averageMap) string(unit string) string {
 
 // averageItem is a simple container for dev's name and some average value
 type averageItem struct {
-	Name  string
-	Value float64
+	Name   string
+	Value  float64
+	Stats  *Stats
+	values []float64
 }
 
 // averageList is used to sort a slice of AverageItems by Value
@@ -93,20 +104,52 @@ func (a averageList) string(unit string) string {
 	}
 
 	result := ""
-	result += fmt.Sprintf("Total average: %.2f %s\n", averageTotal/float64(len(a)), unit)
+	result += fmt.Sprintf(
+		"Total average: %.2f %s%s\n",
+		averageTotal/float64(len(a)),
+		unit,
+		distribution(a.stats()),
+	)
 
 	sort.Sort(sort.Reverse(a))
 
 	for _, dev := range a {
-		result += fmt.Sprintf("Average for %s: %.2f %s\n", dev.Name, dev.Value, unit)
+		result += fmt.Sprintf("Average for %s: %.2f %s%s\n", dev.Name, dev.Value, unit, distribution(dev.Stats))
 	}
 
 	return result
 }
 
+// stats calculates the distribution of the values of all the devs together
+func (a averageList) stats() *Stats {
+	values := []float64{}
+	for _, dev := range a {
+		values = append(values, dev.values...)
+	}
+	return newStats(values)
+}
+
+// distribution formats the most telling part of the stats to be shown next to an average
+func distribution(s *Stats) string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (median %.2f, p90 %.2f)", s.Median, s.P90)
+}
+
 type counterMap map[string]*counter
 
 func (c counterMap) string() string {
+	result := ""
+	for _, i := range c.sorted() {
+		result += fmt.Sprintf("For %s: %d\n", i.Name, i.Count)
+	}
+
+	return result
+}
+
+// sorted returns the counters sorted by Count in descending order
+func (c counterMap) sorted() counters {
 	var cs counters
 
 	for _, i := range c {
@@ -114,13 +157,7 @@ func (c counterMap) string() string {
 	}
 
 	sort.Sort(sort.Reverse(cs))
-
-	result := ""
-	for _, i := range cs {
-		result += fmt.Sprintf("For %s: %d\n", i.Name, i.Count)
-	}
-
-	return result
+	return cs
 }
 
 type counter struct {
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/kirillrogovoy/pullkee/metric"
+)
+
+// CSV renders every entry as a separate comma-separated table
+func CSV(w io.Writer, entries []Entry) error {
+	return delimited(w, entries, ',')
+}
+
+// TSV renders every entry as a separate tab-separated table
+func TSV(w io.Writer, entries []Entry) error {
+	return delimited(w, entries, '\t')
+}
+
+// delimited writes tables one after another divided by an empty line.
+// Each table starts with a row containing the name and the description of the metric
+func delimited(w io.Writer, entries []Entry, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	for i, e := range entries {
+		if i > 0 {
+			cw.Write([]string{})
+		}
+		cw.Write([]string{e.Name, e.Description})
+
+		switch {
+		case e.Error != "":
+			cw.Write([]string{"error", e.Error})
+		case e.Matrix != nil:
+			cw.WriteAll(matrixRows(e))
+		default:
+			stats := hasStats(e)
+			header := []string{"developer", "value" + unitSuffix(e.Unit)}
+			if stats {
+				header = append(header, "median", "p90", "count")
+			}
+			cw.Write(header)
+
+			for _, v := range e.Values {
+				row := []string{v.Developer, formatFloat(v.Value)}
+				if stats {
+					row = append(row, statsCells(v.Stats)...)
+				}
+				cw.Write(row)
+			}
+
+			total := []string{"total", formatFloat(e.Total)}
+			if stats {
+				total = append(total, statsCells(e.Stats)...)
+			}
+			cw.Write(total)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// matrixRows converts the matrix of the entry into a grid where rows are developers
+// from the Values (e.g. authors) and columns are developers from the Matrix (e.g. assignees)
+func matrixRows(e Entry) [][]string {
+	columns := matrixColumns(e.Matrix)
+
+	header := append([]string{"author \\ assignee"}, columns...)
+	rows := [][]string{header}
+
+	for _, v := range e.Values {
+		row := []string{v.Developer}
+		for _, c := range columns {
+			row = append(row, formatFloat(matrixCell(e.Matrix, v.Developer, c)))
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func statsCells(s *metric.Stats) []string {
+	if s == nil {
+		return []string{"", "", ""}
+	}
+	return []string{formatFloat(s.Median), formatFloat(s.P90), strconv.Itoa(s.Count)}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,108 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kirillrogovoy/pullkee/metric"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSV(t *testing.T) {
+	t.Run("Renders a table per metric", func(t *testing.T) {
+		entries := append(testEntries(), Entry{Name: "Broken", Description: "Broken, really", Error: "Oops"})
+
+		b := &bytes.Buffer{}
+		err := CSV(b, entries)
+
+		require.Nil(t, err)
+		require.Equal(
+			t,
+			"metricMock,Mocked metric\n"+
+				"developer,value (PRs)\n"+
+				"User1,2\n"+
+				"total,2\n"+
+				"\n"+
+				"Broken,\"Broken, really\"\n"+
+				"error,Oops\n",
+			b.String(),
+		)
+	})
+
+	t.Run("Renders a matrix as a grid", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		err := CSV(b, []Entry{matrixEntry()})
+
+		require.Nil(t, err)
+		require.Equal(
+			t,
+			"Matrix,Mocked matrix\n"+
+				"author \\ assignee,User1,User2\n"+
+				"User1,0,2.5\n"+
+				"User2,1,3\n",
+			b.String(),
+		)
+	})
+}
+
+func TestCSVStats(t *testing.T) {
+	e := Entry{
+		Name:        "Age",
+		Description: "How old?",
+		Result: metric.Result{
+			Unit:  "days",
+			Total: 2,
+			Values: []metric.Value{
+				{Developer: "User1", Value: 2, Stats: &metric.Stats{Count: 3, Median: 1, P90: 4.5}},
+			},
+			Stats: &metric.Stats{Count: 3, Median: 1, P90: 4.5},
+		},
+	}
+
+	b := &bytes.Buffer{}
+	err := CSV(b, []Entry{e})
+
+	require.Nil(t, err)
+	require.Equal(
+		t,
+		"Age,How old?\n"+
+			"developer,value (days),median,p90,count\n"+
+			"User1,2,1,4.5,3\n"+
+			"total,2,1,4.5,3\n",
+		b.String(),
+	)
+}
+
+func TestTSV(t *testing.T) {
+	b := &bytes.Buffer{}
+	err := TSV(b, testEntries())
+
+	require.Nil(t, err)
+	require.Equal(
+		t,
+		"metricMock\tMocked metric\n"+
+			"developer\tvalue (PRs)\n"+
+			"User1\t2\n"+
+			"total\t2\n",
+		b.String(),
+	)
+}
+
+func matrixEntry() Entry {
+	return Entry{
+		ID:          "matrix",
+		Name:        "Matrix",
+		Description: "Mocked matrix",
+		Result: metric.Result{
+			Unit: "PRs",
+			Values: []metric.Value{
+				{Developer: "User1", Value: 2.5},
+				{Developer: "User2", Value: 4},
+			},
+			Matrix: map[string][]metric.Value{
+				"User1": {{Developer: "User2", Value: 2.5}},
+				"User2": {{Developer: "User2", Value: 3}, {Developer: "User1", Value: 1}},
+			},
+		},
+	}
+}
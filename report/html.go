@@ -0,0 +1,159 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"time"
+)
+
+// HTML renders the entries as a self-contained HTML page with sortable tables,
+// bar charts and heatmaps. It doesn't refer to any external resources
+func HTML(w io.Writer, entries []Entry) error {
+	page := htmlPage{Generated: time.Now().Format(time.RFC1123)}
+
+	for _, e := range entries {
+		he := htmlEntry{Entry: e, HasStats: hasStats(e)}
+		for _, v := range e.Values {
+			he.Max = math.Max(he.Max, v.Value)
+		}
+
+		if e.Matrix != nil {
+			he.Columns = matrixColumns(e.Matrix)
+			for _, v := range e.Values {
+				row := htmlRow{Developer: v.Developer}
+				for _, c := range he.Columns {
+					value := matrixCell(e.Matrix, v.Developer, c)
+					he.MatrixMax = math.Max(he.MatrixMax, value)
+					row.Cells = append(row.Cells, value)
+				}
+				he.Rows = append(he.Rows, row)
+			}
+		}
+
+		page.Entries = append(page.Entries, he)
+	}
+
+	return htmlTemplate.Execute(w, page)
+}
+
+type htmlPage struct {
+	Generated string
+	Entries   []htmlEntry
+}
+
+type htmlEntry struct {
+	Entry
+	HasStats  bool
+	Max       float64
+	Columns   []string
+	Rows      []htmlRow
+	MatrixMax float64
+}
+
+type htmlRow struct {
+	Developer string
+	Cells     []float64
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"number": func(v float64) string {
+		return fmt.Sprintf("%.2f", v)
+	},
+	"percent": func(v float64, max float64) string {
+		if max <= 0 {
+			return "0%"
+		}
+		return fmt.Sprintf("%.1f%%", v/max*100)
+	},
+	"heat": func(v float64, max float64) template.CSS {
+		alpha := 0.0
+		if max > 0 {
+			alpha = v / max
+		}
+		return template.CSS(fmt.Sprintf("background-color: rgba(214, 39, 40, %.2f)", alpha))
+	},
+}).Parse(htmlSource))
+
+const htmlSource = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>pullkee report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1000px; color: #24292e; }
+section { margin-bottom: 3em; }
+h2 { margin-bottom: 0.2em; }
+.description { color: #586069; margin-top: 0; }
+.error { color: #cb2431; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 4px 8px; border-bottom: 1px solid #e1e4e8; text-align: left; }
+th { cursor: pointer; user-select: none; background: #f6f8fa; }
+th.sorted-asc::after { content: " \25B2"; }
+th.sorted-desc::after { content: " \25BC"; }
+td.number { text-align: right; white-space: nowrap; width: 8em; }
+td.chart { width: 50%; }
+.bar { background: #0366d6; height: 1em; }
+.matrix td { text-align: center; }
+footer { color: #586069; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>pullkee report</h1>
+{{range .Entries}}
+<section>
+<h2>{{.Name}}</h2>
+<p class="description">{{.Description}}</p>
+{{if .Error}}
+<p class="error">Error: {{.Error}}</p>
+{{else if .Matrix}}
+{{$max := .MatrixMax}}
+<table class="sortable matrix">
+<thead><tr><th>author \ assignee</th>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr><td>{{.Developer}}</td>{{range .Cells}}<td style="{{heat . $max}}" data-value="{{.}}">{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{else}}
+{{$max := .Max}}
+{{$stats := .HasStats}}
+<p>{{if eq .Aggregation "average"}}Total average{{else}}Total{{end}}: {{number .Total}} {{.Unit}}{{with .Stats}} (median {{number .Median}}, p90 {{number .P90}}){{end}}</p>
+<table class="sortable">
+<thead><tr><th>developer</th><th>{{.Unit}}</th>{{if $stats}}<th>median</th><th>p90</th>{{end}}<th></th></tr></thead>
+<tbody>
+{{range .Values}}<tr><td>{{.Developer}}</td><td class="number" data-value="{{.Value}}">{{number .Value}}</td>{{if $stats}}{{with .Stats}}<td class="number" data-value="{{.Median}}">{{number .Median}}</td><td class="number" data-value="{{.P90}}">{{number .P90}}</td>{{else}}<td></td><td></td>{{end}}{{end}}<td class="chart"><div class="bar" style="width: {{percent .Value $max}}"></div></td></tr>
+{{end}}</tbody>
+</table>
+{{end}}
+</section>
+{{end}}
+<footer>Generated by pullkee on {{.Generated}}</footer>
+<script>
+document.querySelectorAll("table.sortable th").forEach(function (th, _, all) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table");
+    var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+    var desc = !th.classList.contains("sorted-desc");
+    table.querySelectorAll("th").forEach(function (h) { h.classList.remove("sorted-asc", "sorted-desc"); });
+    th.classList.add(desc ? "sorted-desc" : "sorted-asc");
+
+    var key = function (row) {
+      var cell = row.children[index];
+      var value = cell.getAttribute("data-value");
+      return value === null ? cell.textContent : parseFloat(value);
+    };
+    var tbody = table.tBodies[0];
+    Array.prototype.slice.call(tbody.rows)
+      .sort(function (a, b) {
+        var x = key(a), y = key(b);
+        var cmp = x < y ? -1 : x > y ? 1 : 0;
+        return desc ? -cmp : cmp;
+      })
+      .forEach(function (row) { tbody.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,29 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTML(t *testing.T) {
+	entries := append(testEntries(), matrixEntry(), Entry{Name: "Broken", Error: "<Oops>"})
+
+	b := &bytes.Buffer{}
+	err := HTML(b, entries)
+	require.Nil(t, err)
+
+	html := b.String()
+
+	require.Contains(t, html, "<h2>metricMock</h2>")
+	require.Contains(t, html, `<div class="bar" style="width: 100.0%"></div>`)
+
+	require.Contains(t, html, "<th>User1</th><th>User2</th>")
+	require.Contains(t, html, `<td style="background-color: rgba(214, 39, 40, 1.00)" data-value="3">3</td>`)
+	require.Contains(t, html, `<td style="background-color: rgba(214, 39, 40, 0.00)" data-value="0">0</td>`)
+
+	require.Contains(t, html, "Error: &lt;Oops&gt;")
+	require.NotContains(t, html, "http://")
+	require.NotContains(t, html, "https://")
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kirillrogovoy/pullkee/metric"
+)
+
+// Markdown renders every entry as a GitHub-flavoured markdown table with a summary line
+func Markdown(w io.Writer, entries []Entry) error {
+	result := ""
+
+	for _, e := range entries {
+		result += fmt.Sprintf("### %s\n\n%s\n\n", escapeMarkdown(e.Name), escapeMarkdown(e.Description))
+
+		switch {
+		case e.Error != "":
+			result += fmt.Sprintf("**Error:** %s\n\n", escapeMarkdown(e.Error))
+		case e.Matrix != nil:
+			result += markdownTable(matrixRows(e))
+			result += "\n" + summary(e) + "\n\n"
+		default:
+			stats := hasStats(e)
+			header := []string{"Developer", "Value" + unitSuffix(e.Unit)}
+			if stats {
+				header = append(header, "Median", "p90")
+			}
+
+			rows := [][]string{header}
+			for _, v := range e.Values {
+				row := []string{v.Developer, formatValue(e, v.Value)}
+				if stats && v.Stats != nil {
+					row = append(row, formatValue(e, v.Stats.Median), formatValue(e, v.Stats.P90))
+				} else if stats {
+					row = append(row, "", "")
+				}
+				rows = append(rows, row)
+			}
+			result += markdownTable(rows)
+			result += "\n" + summary(e) + "\n\n"
+		}
+	}
+
+	_, err := io.WriteString(w, result)
+	return err
+}
+
+// markdownTable renders the first row as the header of the table and the rest as its body
+func markdownTable(rows [][]string) string {
+	result := ""
+
+	for i, row := range rows {
+		cells := []string{}
+		for _, cell := range row {
+			cells = append(cells, escapeMarkdown(cell))
+		}
+		result += fmt.Sprintf("| %s |\n", strings.Join(cells, " | "))
+
+		if i == 0 {
+			result += strings.Repeat("| --- ", len(row)) + "|\n"
+		}
+	}
+
+	return result
+}
+
+func summary(e Entry) string {
+	if e.Aggregation == metric.Average && e.Stats != nil {
+		return fmt.Sprintf(
+			"**Total average:** %s %s (median %s, p90 %s)",
+			formatValue(e, e.Total),
+			e.Unit,
+			formatValue(e, e.Stats.Median),
+			formatValue(e, e.Stats.P90),
+		)
+	}
+	if e.Aggregation == metric.Average {
+		return fmt.Sprintf("**Total average:** %s %s", formatValue(e, e.Total), e.Unit)
+	}
+	return fmt.Sprintf("**Total:** %s %s", formatValue(e, e.Total), e.Unit)
+}
+
+// formatValue formats averages with a fixed precision and sums as they are
+func formatValue(e Entry, v float64) string {
+	if e.Aggregation == metric.Average {
+		return fmt.Sprintf("%.2f", v)
+	}
+	return formatFloat(v)
+}
+
+// a line break would end a table row or a paragraph, so it becomes an HTML one
+var markdownEscaper = strings.NewReplacer(
+	`|`, `\|`, `*`, `\*`, `_`, `\_`, "`", "\\`", `\`, `\\`,
+	"\r\n", "<br>", "\n", "<br>", "\r", "<br>",
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
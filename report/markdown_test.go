@@ -0,0 +1,77 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kirillrogovoy/pullkee/metric"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdown(t *testing.T) {
+	t.Run("Renders averages and sums", func(t *testing.T) {
+		entries := []Entry{
+			{
+				Name:        "Age",
+				Description: "How old is a PR?",
+				Result: metric.Result{
+					Unit:        "days",
+					Aggregation: metric.Average,
+					Total:       1.5,
+					Values: []metric.Value{
+						{Developer: "User_1", Value: 2, Stats: &metric.Stats{Median: 1, P90: 3}},
+						{Developer: "User2", Value: 1},
+					},
+					Stats: &metric.Stats{Median: 1, P90: 2.5},
+				},
+			},
+			testEntries()[0],
+			{Name: "Broken", Description: "Broken metric", Error: "Oops"},
+		}
+
+		b := &bytes.Buffer{}
+		err := Markdown(b, entries)
+
+		require.Nil(t, err)
+		require.Equal(
+			t,
+			"### Age\n\nHow old is a PR?\n\n"+
+				"| Developer | Value (days) | Median | p90 |\n"+
+				"| --- | --- | --- | --- |\n"+
+				"| User\\_1 | 2.00 | 1.00 | 3.00 |\n"+
+				"| User2 | 1.00 |  |  |\n"+
+				"\n**Total average:** 1.50 days (median 1.00, p90 2.50)\n\n"+
+				"### metricMock\n\nMocked metric\n\n"+
+				"| Developer | Value (PRs) |\n"+
+				"| --- | --- |\n"+
+				"| User1 | 2 |\n"+
+				"\n**Total:** 2 PRs\n\n"+
+				"### Broken\n\nBroken metric\n\n"+
+				"**Error:** Oops\n\n",
+			b.String(),
+		)
+	})
+
+	t.Run("Renders a matrix as a grid", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		err := Markdown(b, []Entry{matrixEntry()})
+
+		require.Nil(t, err)
+		require.Contains(
+			t,
+			b.String(),
+			"| author \\\\ assignee | User1 | User2 |\n"+
+				"| --- | --- | --- |\n"+
+				"| User1 | 0 | 2.5 |\n"+
+				"| User2 | 1 | 3 |\n",
+		)
+	})
+
+	t.Run("Keeps a multi-line error in one paragraph", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		err := Markdown(b, []Entry{{Name: "Broken", Error: "Wrong HTTP response code 500\r\nDetails:\nOops"}})
+
+		require.Nil(t, err)
+		require.Contains(t, b.String(), "**Error:** Wrong HTTP response code 500<br>Details:<br>Oops\n\n")
+	})
+}
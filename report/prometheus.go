@@ -0,0 +1,92 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Prometheus returns a Renderer which writes the entries in the Prometheus text exposition format.
+// Every metric becomes a gauge labeled by `repo` and `developer` and named after its identifier and unit,
+// the totals become separate "_overall" gauges and the matrices are labeled by `author` and `assignee`
+func Prometheus(repo string) Renderer {
+	return func(w io.Writer, entries []Entry) error {
+		result := ""
+
+		for _, e := range entries {
+			if e.Error != "" {
+				continue
+			}
+
+			base, unit := prometheusName(e.ID, e.Unit)
+			name := base + unit
+			help := fmt.Sprintf("%s (%s)", e.Description, e.Unit)
+
+			result += fmt.Sprintf("# HELP %s %s\n# TYPE %s gauge\n", name, escapeHelp(help), name)
+			if e.Matrix != nil {
+				for _, author := range e.Values {
+					for _, v := range e.Matrix[author.Developer] {
+						result += sample(name, v.Value, "repo", repo, "author", author.Developer, "assignee", v.Developer)
+					}
+				}
+			} else {
+				for _, v := range e.Values {
+					result += sample(name, v.Value, "repo", repo, "developer", v.Developer)
+				}
+			}
+
+			overall := base + "_overall" + unit
+			result += fmt.Sprintf("# HELP %s %s\n# TYPE %s gauge\n", overall, escapeHelp(help+", "+string(e.Aggregation)), overall)
+			result += sample(overall, e.Total, "repo", repo)
+		}
+
+		_, err := io.WriteString(w, result)
+		return err
+	}
+}
+
+func sample(name string, value float64, labels ...string) string {
+	pairs := []string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labels[i], escapeLabel(labels[i+1])))
+	}
+	return fmt.Sprintf("%s{%s} %s\n", name, strings.Join(pairs, ","), formatFloat(value))
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// prometheusName converts a metric identifier and its unit to the name of the gauge and the unit suffix,
+// e.g. "diff-size-per-day" in "bytes/day" to "pullkee_diff_size" and "_bytes_per_day".
+// A unit the identifier already ends with isn't repeated
+func prometheusName(id, unit string) (string, string) {
+	name := "pullkee_" + prometheusWord(id)
+	suffix := prometheusWord(strings.Replace(unit, "/", "_per_", -1))
+	if i := strings.Index(suffix, "_per_"); i >= 0 {
+		numerator, rate := suffix[:i], suffix[i:]
+		name = strings.TrimSuffix(name, rate)
+		if strings.HasSuffix(name, "_"+numerator) {
+			return name, rate
+		}
+	}
+
+	if suffix == "" || strings.HasSuffix(name, "_"+suffix) {
+		return name, ""
+	}
+	return name, "_" + suffix
+}
+
+func prometheusWord(s string) string {
+	return strings.Trim(strings.ToLower(invalidNameChars.ReplaceAllString(s, "_")), "_")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kirillrogovoy/pullkee/metric"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheus(t *testing.T) {
+	t.Run("Renders gauges per developer and the totals", func(t *testing.T) {
+		entries := []Entry{
+			{
+				ID:          "diff-size-per-day",
+				Name:        "DiffSizePerDay",
+				Description: "How many bytes/day?",
+				Result: metric.Result{
+					Unit:        "bytes/day",
+					Aggregation: metric.Average,
+					Total:       1.5,
+					Values: []metric.Value{
+						{Developer: "User1", Value: 2},
+						{Developer: `Us"er2`, Value: 1},
+					},
+				},
+			},
+			{Name: "Broken", Error: "Oops"},
+		}
+
+		b := &bytes.Buffer{}
+		err := Prometheus("someuser/somerepo")(b, entries)
+
+		require.Nil(t, err)
+		require.Equal(
+			t,
+			"# HELP pullkee_diff_size_bytes_per_day How many bytes/day? (bytes/day)\n"+
+				"# TYPE pullkee_diff_size_bytes_per_day gauge\n"+
+				"pullkee_diff_size_bytes_per_day{repo=\"someuser/somerepo\",developer=\"User1\"} 2\n"+
+				"pullkee_diff_size_bytes_per_day{repo=\"someuser/somerepo\",developer=\"Us\\\"er2\"} 1\n"+
+				"# HELP pullkee_diff_size_overall_bytes_per_day How many bytes/day? (bytes/day), average\n"+
+				"# TYPE pullkee_diff_size_overall_bytes_per_day gauge\n"+
+				"pullkee_diff_size_overall_bytes_per_day{repo=\"someuser/somerepo\"} 1.5\n",
+			b.String(),
+		)
+	})
+
+	t.Run("Renders a matrix with author and assignee labels", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		err := Prometheus("someuser/somerepo")(b, []Entry{matrixEntry()})
+
+		require.Nil(t, err)
+		require.Contains(t, b.String(), "pullkee_matrix_prs{repo=\"someuser/somerepo\",author=\"User1\",assignee=\"User2\"} 2.5\n")
+		require.Contains(t, b.String(), "pullkee_matrix_prs{repo=\"someuser/somerepo\",author=\"User2\",assignee=\"User1\"} 1\n")
+	})
+}
+
+func TestPrometheusName(t *testing.T) {
+	cases := []struct {
+		id, unit, name, suffix string
+	}{
+		{"age", "days", "pullkee_age", "_days"},
+		{"first-review", "hours", "pullkee_first_review", "_hours"},
+		{"diff-size", "bytes", "pullkee_diff_size", "_bytes"},
+		{"diff-size-per-day", "bytes/day", "pullkee_diff_size", "_bytes_per_day"},
+		{"comment-chars-per-day", "chars/day", "pullkee_comment_chars", "_per_day"},
+		{"author-comments", "comments", "pullkee_author_comments", ""},
+		{"assignee", "PRs", "pullkee_assignee", "_prs"},
+		{"age", "", "pullkee_age", ""},
+	}
+
+	for _, c := range cases {
+		name, suffix := prometheusName(c.id, c.unit)
+		require.Equal(t, c.name, name, c.id)
+		require.Equal(t, c.suffix, suffix, c.id)
+	}
+}
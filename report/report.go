@@ -0,0 +1,141 @@
+// Package report renders the calculated metrics in different output formats
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kirillrogovoy/pullkee/github"
+	"github.com/kirillrogovoy/pullkee/metric"
+)
+
+// Entry is a calculated metric ready to be rendered
+type Entry struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Error       string `json:"error,omitempty"`
+	metric.Result
+	text string
+}
+
+// Renderer writes the entries to `w` in some particular format
+type Renderer func(w io.Writer, entries []Entry) error
+
+// Renderers contains all the supported output formats
+var Renderers = map[string]Renderer{
+	"text":     Text,
+	"json":     JSON,
+	"csv":      CSV,
+	"tsv":      TSV,
+	"html":     HTML,
+	"markdown": Markdown,
+}
+
+// Formats returns the sorted list of the supported output formats
+func Formats() []string {
+	formats := []string{}
+	for f := range Renderers {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Build calculates every metric over `pullRequests` and collects the results
+func Build(metrics []metric.Metric, pullRequests []github.PullRequest) []Entry {
+	entries := []Entry{}
+
+	for _, m := range metrics {
+		e := Entry{
+			ID:          metric.ID(m),
+			Name:        reflect.TypeOf(m).Elem().Name(),
+			Description: m.Description(),
+		}
+
+		if err := m.Calculate(pullRequests); err != nil {
+			e.Error = err.Error()
+		} else {
+			e.Result = m.Result()
+			e.text = m.String()
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// Text renders the entries in a human-readable form
+func Text(w io.Writer, entries []Entry) error {
+	for _, e := range entries {
+		result := e.text
+		if e.Error != "" {
+			result = fmt.Sprintf("Error: %s\n", e.Error)
+		}
+
+		if _, err := fmt.Fprintf(w, "Metric '%s' (%s)\n%s\n", e.Name, e.Description, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JSON renders the entries as a single JSON document
+func JSON(w io.Writer, entries []Entry) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Metrics []Entry `json:"metrics"`
+	}{entries})
+}
+
+// unitSuffix returns the unit in the form suitable to be a part of a column header
+func unitSuffix(unit string) string {
+	if unit == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.TrimSpace(unit))
+}
+
+// matrixColumns returns the sorted list of all the developers found in the rows of the matrix
+func matrixColumns(matrix map[string][]metric.Value) []string {
+	seen := map[string]bool{}
+	columns := []string{}
+
+	for _, values := range matrix {
+		for _, v := range values {
+			if !seen[v.Developer] {
+				seen[v.Developer] = true
+				columns = append(columns, v.Developer)
+			}
+		}
+	}
+
+	sort.Strings(columns)
+	return columns
+}
+
+// matrixCell returns the value of the matrix in the given row and column or zero if there is none
+func matrixCell(matrix map[string][]metric.Value, row string, column string) float64 {
+	for _, v := range matrix[row] {
+		if v.Developer == column {
+			return v.Value
+		}
+	}
+	return 0
+}
+
+// hasStats tells if the values of the entry come with their distribution
+func hasStats(e Entry) bool {
+	for _, v := range e.Values {
+		if v.Stats != nil {
+			return true
+		}
+	}
+	return false
+}
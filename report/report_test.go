@@ -0,0 +1,98 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kirillrogovoy/pullkee/github"
+	"github.com/kirillrogovoy/pullkee/metric"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	t.Run("Collects the results of the metrics", func(t *testing.T) {
+		entries := Build([]metric.Metric{&metricMock{}}, []github.PullRequest{{}, {}})
+
+		require.Len(t, entries, 1)
+		require.Equal(t, "metricMock", entries[0].Name)
+		require.Equal(t, "Mocked metric", entries[0].Description)
+		require.Equal(t, 2.0, entries[0].Total)
+		require.Equal(t, "For User1: 2\n", entries[0].text)
+	})
+
+	t.Run("Saves the error if the metric couldn't be calculated", func(t *testing.T) {
+		entries := Build([]metric.Metric{&metricMock{err: fmt.Errorf("Not enough data")}}, nil)
+
+		require.Len(t, entries, 1)
+		require.Equal(t, "Not enough data", entries[0].Error)
+	})
+}
+
+func TestText(t *testing.T) {
+	entries := append(testEntries(), Entry{Name: "Broken", Description: "Broken metric", Error: "Oops"})
+
+	b := &bytes.Buffer{}
+	err := Text(b, entries)
+
+	require.Nil(t, err)
+	require.Equal(
+		t,
+		"Metric 'metricMock' (Mocked metric)\nFor User1: 2\n\nMetric 'Broken' (Broken metric)\nError: Oops\n\n",
+		b.String(),
+	)
+}
+
+func TestJSON(t *testing.T) {
+	b := &bytes.Buffer{}
+	err := JSON(b, testEntries())
+	require.Nil(t, err)
+
+	decoded := map[string][]map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(b.Bytes(), &decoded))
+
+	m := decoded["metrics"][0]
+	require.Equal(t, "metricMock", m["name"])
+	require.Equal(t, "Mocked metric", m["description"])
+	require.Equal(t, "PRs", m["unit"])
+	require.Equal(t, 2.0, m["total"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"developer": "User1", "value": 2.0},
+	}, m["values"])
+	require.Nil(t, m["error"])
+}
+
+func testEntries() []Entry {
+	return Build([]metric.Metric{&metricMock{}}, []github.PullRequest{{}, {}})
+}
+
+type metricMock struct {
+	count int
+	err   error
+}
+
+func (m *metricMock) Description() string {
+	return "Mocked metric"
+}
+
+func (m *metricMock) Details() []github.Detail {
+	return nil
+}
+
+func (m *metricMock) Calculate(pullRequests []github.PullRequest) error {
+	m.count = len(pullRequests)
+	return m.err
+}
+
+func (m *metricMock) String() string {
+	return fmt.Sprintf("For User1: %d\n", m.count)
+}
+
+func (m *metricMock) Result() metric.Result {
+	return metric.Result{
+		Unit:   "PRs",
+		Total:  float64(m.count),
+		Values: []metric.Value{{Developer: "User1", Value: float64(m.count)}},
+	}
+}